@@ -57,7 +57,8 @@ func NewCheckedMessage(logger Logger, lvl Level, msg string) *CheckedMessage {
 // Write logs the pre-checked message with the supplied fields. It will call
 // the underlying level method (Debug, Info, Warn, Error, DPanic, Panic, and
 // Fatal) for the defined levels; the Log method is only called for unknown
-// logging levels.
+// logging levels. If writing a chained message at DPanicLevel panics, the
+// panic is deferred until the rest of the chain has been written.
 //
 // It MUST be called at most once, since Write will return the *CheckedMessage
 // to an internal pool for potentially immediate re-use; re-using a
@@ -83,6 +84,10 @@ func (m *CheckedMessage) Write(fields ...Field) {
 	}
 	m.safeToWrite = false
 
+	var (
+		recovered interface{}
+		panicked  bool
+	)
 	switch m.lvl {
 	case DebugLevel:
 		m.logger.Debug(m.msg, fields...)
@@ -92,6 +97,8 @@ func (m *CheckedMessage) Write(fields ...Field) {
 		m.logger.Warn(m.msg, fields...)
 	case ErrorLevel:
 		m.logger.Error(m.msg, fields...)
+	case DPanicLevel:
+		recovered, panicked = catchDPanic(m.logger, m.msg, fields)
 	case PanicLevel:
 		m.logger.Panic(m.msg, fields...)
 	case FatalLevel:
@@ -100,9 +107,14 @@ func (m *CheckedMessage) Write(fields ...Field) {
 		m.logger.Log(m.lvl, m.msg, fields...)
 	}
 
-	m.next.Write(fields...)
+	next := m.next
 	m.next, m.tail = nil, nil
 	_cmPool.Put(m)
+
+	next.Write(fields...)
+	if panicked {
+		panic(recovered)
+	}
 }
 
 // Chain combines two or more CheckedMessages. If the receiver message is not
@@ -139,6 +151,17 @@ func (m *CheckedMessage) push(next *CheckedMessage) {
 	}
 }
 
+// release returns a CheckedMessage that won't be written, along with any
+// messages chained to it, to the pool.
+func (m *CheckedMessage) release() {
+	for m != nil {
+		next := m.next
+		m.safeToWrite, m.next, m.tail = false, nil, nil
+		_cmPool.Put(m)
+		m = next
+	}
+}
+
 // OK checks whether it's safe to call Write.
 func (m *CheckedMessage) OK() bool {
 	return m != nil
@@ -198,3 +198,29 @@ func TestCheckedMessage_Chain(t *testing.T) {
 		}
 	})
 }
+
+func TestCheckedMessageDPanic(t *testing.T) {
+	withJSONLogger(t, opts(InfoLevel), func(logger Logger, buf *testBuffer) {
+		assert.NotPanics(t, func() { logger.Check(DPanicLevel, "prod").Write() }, "Unexpected panic in production.")
+		assert.Equal(t, `{"level":"dpanic","msg":"prod"}`, buf.Stripped(), "Unexpected output from Check(DPanicLevel).")
+	})
+
+	withJSONLogger(t, opts(Development(), FatalLevel), func(logger Logger, buf *testBuffer) {
+		cm := logger.Check(DPanicLevel, "dev")
+		require.True(t, cm.OK(), "Expected Check(DPanicLevel) to be OK in development, even if the level is disabled.")
+		assert.Panics(t, func() { cm.Write() }, "Expected Check(DPanicLevel).Write() to panic in development.")
+	})
+}
+
+func TestCheckedMessageChainDPanic(t *testing.T) {
+	buf := &testBuffer{}
+	dev := New(newJSONEncoder(NoTime()), Development(), Output(buf), Fields(String("name", "dev")))
+	prod := New(newJSONEncoder(NoTime()), Output(buf), Fields(String("name", "prod")))
+
+	cm := dev.Check(DPanicLevel, "chained").Chain(prod.Check(DPanicLevel, "chained"))
+	assert.Panics(t, func() { cm.Write() }, "Expected a chain with a development logger to panic.")
+	assert.Equal(t, []string{
+		`{"level":"dpanic","msg":"chained","name":"dev"}`,
+		`{"level":"dpanic","msg":"chained","name":"prod"}`,
+	}, buf.Lines(), "Expected the whole chain to be written before panicking.")
+}
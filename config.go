@@ -0,0 +1,519 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// _defaultSampleTick is the sampling interval used when a SamplingConfig
+// doesn't specify one.
+const _defaultSampleTick = time.Second
+
+// A Config declaratively describes a Logger. It can be unmarshaled from JSON
+// or YAML; for example:
+//
+//   level: warn
+//   encoding: json
+//   encoderConfig:
+//     timeFormat: rfc3339
+//   outputPaths: [stdout, /var/log/app.log]
+//   sampling:
+//     initial: 100
+//     thereafter: 100
+//   caller: true
+//   initialFields:
+//     service: app
+//
+// Unmarshaling reports invalid levels, encodings, and sampling values with a
+// configError naming their key. The zero Config builds a Logger equivalent to
+// New(NewJSONEncoder()).
+type Config struct {
+	// Level is the minimum enabled logging level. Since it's an AtomicLevel,
+	// it can be changed after the Logger is built. If unset, Build uses a new
+	// AtomicLevel at InfoLevel.
+	Level AtomicLevel `json:"level" yaml:"level"`
+	// Development puts the Logger in development mode (see Development).
+	Development bool `json:"development" yaml:"development"`
+	// Encoding is "json" (the default), "text", or "console", which is the
+	// text encoding with multi-line values broken out (see TextMultiline).
+	Encoding string `json:"encoding" yaml:"encoding"`
+	// EncoderConfig tunes the chosen encoding.
+	EncoderConfig EncoderConfig `json:"encoderConfig" yaml:"encoderConfig"`
+	// OutputPaths and ErrorOutputPaths list the files that entries and
+	// internal errors are written to. "stdout" and "stderr" name the standard
+	// streams. They default to standard out and standard error, respectively.
+	OutputPaths      []string `json:"outputPaths" yaml:"outputPaths"`
+	ErrorOutputPaths []string `json:"errorOutputPaths" yaml:"errorOutputPaths"`
+	// Sampling, if set, wraps the Logger with Sample.
+	Sampling *SamplingConfig `json:"sampling" yaml:"sampling"`
+	// Caller annotates entries with their caller (see AddCallerField).
+	Caller bool `json:"caller" yaml:"caller"`
+	// Levels sets the levels of Named loggers, keyed by LevelRegistry pattern.
+	// If it's set, the Logger's levels are managed by a LevelRegistry whose
+	// default level is Level, so Level.SetLevel still changes the level of
+	// loggers that no pattern applies to.
+	Levels map[string]Level `json:"levels" yaml:"levels"`
+	// StacktraceLevel, if set, records stack traces for entries at or above
+	// the given level (see AddStacks).
+	StacktraceLevel string `json:"stacktraceLevel" yaml:"stacktraceLevel"`
+	// InitialFields are added to every entry.
+	InitialFields map[string]interface{} `json:"initialFields" yaml:"initialFields"`
+}
+
+// EncoderConfig tunes a Config's encoder. Empty values keep the encoder's
+// defaults.
+type EncoderConfig struct {
+	// MessageKey, LevelKey, TimeKey, and NameKey rename the corresponding keys
+	// of the JSON encoding. They're ignored by the other encodings.
+	MessageKey string `json:"messageKey" yaml:"messageKey"`
+	LevelKey   string `json:"levelKey" yaml:"levelKey"`
+	TimeKey    string `json:"timeKey" yaml:"timeKey"`
+	NameKey    string `json:"nameKey" yaml:"nameKey"`
+	// TimeFormat is "epoch" (the JSON default; not supported by the other
+	// encodings), "rfc3339", "iso8601", or "none".
+	TimeFormat string `json:"timeFormat" yaml:"timeFormat"`
+	// SortKeys sorts each entry's context by key.
+	SortKeys bool `json:"sortKeys" yaml:"sortKeys"`
+}
+
+// SamplingConfig configures Sample.
+type SamplingConfig struct {
+	// Tick is the sampling interval. It defaults to one second. Both JSON and
+	// YAML accept a string parsed by time.ParseDuration (e.g., "1s") or an
+	// integer number of nanoseconds.
+	Tick       time.Duration `json:"tick" yaml:"tick"`
+	Initial    int           `json:"initial" yaml:"initial"`
+	Thereafter int           `json:"thereafter" yaml:"thereafter"`
+}
+
+// NewProductionConfig returns a Config for production use: JSON output to
+// standard out at InfoLevel, ISO8601 timestamps, caller annotations, stack
+// traces at ErrorLevel and above, and sampling of 100 entries per message per
+// second, then every 100th.
+func NewProductionConfig() Config {
+	return Config{
+		Level:            DynamicLevel(),
+		Encoding:         "json",
+		EncoderConfig:    EncoderConfig{TimeFormat: "iso8601"},
+		OutputPaths:      []string{"stdout"},
+		ErrorOutputPaths: []string{"stderr"},
+		Sampling: &SamplingConfig{
+			Tick:       _defaultSampleTick,
+			Initial:    100,
+			Thereafter: 100,
+		},
+		Caller:          true,
+		StacktraceLevel: ErrorLevel.String(),
+	}
+}
+
+// NewDevelopmentConfig returns a Config for development: console output to
+// standard out at DebugLevel, ISO8601 timestamps, stack traces at WarnLevel
+// and above, and development mode, so DPanic panics.
+func NewDevelopmentConfig() Config {
+	lvl := DynamicLevel()
+	lvl.SetLevel(DebugLevel)
+	return Config{
+		Level:            lvl,
+		Development:      true,
+		Encoding:         "console",
+		EncoderConfig:    EncoderConfig{TimeFormat: "iso8601"},
+		OutputPaths:      []string{"stdout"},
+		ErrorOutputPaths: []string{"stderr"},
+		StacktraceLevel:  WarnLevel.String(),
+	}
+}
+
+// NewProduction builds a Logger from NewProductionConfig, applying any
+// additional options after the preset ones.
+func NewProduction(opts ...Option) (Logger, error) {
+	return NewProductionConfig().Build(opts...)
+}
+
+// NewDevelopment builds a Logger from NewDevelopmentConfig, applying any
+// additional options after the preset ones.
+func NewDevelopment(opts ...Option) (Logger, error) {
+	return NewDevelopmentConfig().Build(opts...)
+}
+
+// A configError reports an invalid Config value, naming its key.
+type configError struct {
+	key string
+	err error
+}
+
+func (e configError) Error() string {
+	return fmt.Sprintf("invalid logger configuration key %q: %v", e.key, e.err)
+}
+
+// configEnums holds the Config keys whose values are names from a fixed set,
+// so that an unknown one can be reported with its key before the full Config
+// is unmarshaled.
+type configEnums struct {
+	Level           *string           `json:"level" yaml:"level"`
+	Levels          map[string]string `json:"levels" yaml:"levels"`
+	Encoding        string            `json:"encoding" yaml:"encoding"`
+	StacktraceLevel string            `json:"stacktraceLevel" yaml:"stacktraceLevel"`
+}
+
+func (c configEnums) validate() error {
+	var lvl Level
+	if c.Level != nil {
+		if err := lvl.UnmarshalText([]byte(*c.Level)); err != nil {
+			return configError{"level", err}
+		}
+	}
+	patterns := make([]string, 0, len(c.Levels))
+	for pattern := range c.Levels {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if err := lvl.UnmarshalText([]byte(c.Levels[pattern])); err != nil {
+			return configError{"levels." + pattern, err}
+		}
+	}
+	if c.Encoding != "" && !knownEncoding(c.Encoding) {
+		return configError{"encoding", fmt.Errorf("unknown encoding %q", c.Encoding)}
+	}
+	if c.StacktraceLevel != "" {
+		if err := lvl.UnmarshalText([]byte(c.StacktraceLevel)); err != nil {
+			return configError{"stacktraceLevel", err}
+		}
+	}
+	return nil
+}
+
+// config has Config's fields but not its methods, so the unmarshaling methods
+// can fall back to the default behavior.
+type config Config
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (cfg *Config) UnmarshalJSON(data []byte) error {
+	// Values of the wrong type are reported by the full unmarshal below.
+	var enums configEnums
+	if json.Unmarshal(data, &enums) == nil {
+		if err := enums.validate(); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, (*config)(cfg))
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (cfg *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enums configEnums
+	if unmarshal(&enums) == nil {
+		if err := enums.validate(); err != nil {
+			return err
+		}
+	}
+	return unmarshal((*config)(cfg))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting the Tick as either a
+// duration string or an integer number of nanoseconds. Invalid values are
+// reported with their key, such as "sampling.initial".
+func (s *SamplingConfig) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return configError{"sampling", err}
+	}
+	for key, value := range raw {
+		// Match keys case-insensitively, like encoding/json.
+		var err error
+		switch key = strings.ToLower(key); key {
+		case "tick":
+			err = s.unmarshalTick(value)
+		case "initial":
+			err = json.Unmarshal(value, &s.Initial)
+		case "thereafter":
+			err = json.Unmarshal(value, &s.Thereafter)
+		}
+		if err != nil {
+			return configError{"sampling." + key, err}
+		}
+	}
+	return nil
+}
+
+func (s *SamplingConfig) unmarshalTick(data json.RawMessage) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		tick, err := time.ParseDuration(str)
+		if err != nil {
+			return err
+		}
+		s.Tick = tick
+		return nil
+	}
+	var nanos int64
+	if err := json.Unmarshal(data, &nanos); err != nil {
+		return fmt.Errorf("must be a duration string or integer nanoseconds, got %s", data)
+	}
+	s.Tick = time.Duration(nanos)
+	return nil
+}
+
+// Build constructs a Logger from the Config, applying any additional options
+// after the configured ones. It returns an error, naming the offending key,
+// if the Config is invalid or an output can't be opened. Files opened for the
+// Logger's outputs stay open for the life of the process; use Open to close
+// them sooner.
+func (cfg Config) Build(opts ...Option) (Logger, error) {
+	log, _, err := cfg.Open(opts...)
+	return log, err
+}
+
+// Open is like Build, but it also returns a function that closes the files
+// opened for the Logger's outputs. Call it once the Logger is no longer in
+// use; the standard streams are left open.
+func (cfg Config) Open(opts ...Option) (Logger, func() error, error) {
+	enc, err := cfg.buildEncoder()
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Sampling != nil {
+		if err := cfg.Sampling.validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	lvl := cfg.Level
+	if lvl.l == nil {
+		lvl = DynamicLevel()
+	}
+	base := []Option{lvl}
+	if len(cfg.Levels) > 0 {
+		registry := newLevelRegistry(lvl)
+		for pattern, l := range cfg.Levels {
+			if err := registry.SetLevel(pattern, l); err != nil {
+				return nil, nil, configError{"levels." + pattern, err}
+			}
+		}
+		base[0] = registry
+	}
+	if cfg.Development {
+		base = append(base, Development())
+	}
+	if cfg.Caller {
+		base = append(base, AddCallerField())
+	}
+	if cfg.StacktraceLevel != "" {
+		var stackLvl Level
+		if err := stackLvl.UnmarshalText([]byte(cfg.StacktraceLevel)); err != nil {
+			return nil, nil, configError{"stacktraceLevel", err}
+		}
+		base = append(base, AddStacks(stackLvl))
+	}
+	if len(cfg.InitialFields) > 0 {
+		base = append(base, Fields(initialFields(cfg.InitialFields)...))
+	}
+	// Outputs are opened after everything else is validated, so only a
+	// failure to open the error output needs to close them again.
+	closeOut := func() error { return nil }
+	if len(cfg.OutputPaths) > 0 {
+		out, closeFiles, err := openPaths(cfg.OutputPaths)
+		if err != nil {
+			return nil, nil, configError{"outputPaths", err}
+		}
+		base = append(base, Output(out))
+		closeOut = closeFiles
+	}
+	closeErrOut := func() error { return nil }
+	if len(cfg.ErrorOutputPaths) > 0 {
+		errOut, closeFiles, err := openPaths(cfg.ErrorOutputPaths)
+		if err != nil {
+			closeOut()
+			return nil, nil, configError{"errorOutputPaths", err}
+		}
+		base = append(base, ErrorOutput(errOut))
+		closeErrOut = closeFiles
+	}
+	closeAll := func() error {
+		var errs multiError
+		for _, f := range []func() error{closeOut, closeErrOut} {
+			if err := f(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errs.asError()
+	}
+
+	log := New(enc, append(base, opts...)...)
+	if s := cfg.Sampling; s != nil {
+		tick := s.Tick
+		if tick == 0 {
+			tick = _defaultSampleTick
+		}
+		log = Sample(log, tick, s.Initial, s.Thereafter)
+	}
+	return log, closeAll, nil
+}
+
+func (cfg Config) buildEncoder() (Encoder, error) {
+	ec := cfg.EncoderConfig
+	switch cfg.Encoding {
+	case "", "json":
+		var opts []JSONOption
+		if ec.MessageKey != "" {
+			opts = append(opts, MessageKey(ec.MessageKey))
+		}
+		if ec.LevelKey != "" {
+			opts = append(opts, LevelString(ec.LevelKey))
+		}
+		if ec.NameKey != "" {
+			opts = append(opts, NameKey(ec.NameKey))
+		}
+		timeKey := ec.TimeKey
+		if timeKey == "" {
+			timeKey = "ts"
+		}
+		switch ec.TimeFormat {
+		case "", "epoch":
+			if ec.TimeKey != "" {
+				opts = append(opts, EpochFormatter(timeKey))
+			}
+		case "rfc3339":
+			opts = append(opts, RFC3339Formatter(timeKey))
+		case "iso8601":
+			opts = append(opts, ISO8601Formatter(timeKey))
+		case "none":
+			opts = append(opts, NoTime())
+		default:
+			return nil, configError{"encoderConfig.timeFormat", fmt.Errorf("unknown time format %q", ec.TimeFormat)}
+		}
+		if ec.SortKeys {
+			opts = append(opts, SortKeys())
+		}
+		return NewJSONEncoder(opts...), nil
+	case "text", "console":
+		var opts []TextOption
+		switch ec.TimeFormat {
+		case "":
+		case "rfc3339":
+			opts = append(opts, TextTimeFormat(time.RFC3339))
+		case "iso8601":
+			opts = append(opts, TextTimeFormat(_iso8601Layout))
+		case "none":
+			opts = append(opts, TextNoTime())
+		default:
+			return nil, configError{"encoderConfig.timeFormat", fmt.Errorf("unknown time format %q for %s encoding", ec.TimeFormat, cfg.Encoding)}
+		}
+		if ec.SortKeys {
+			opts = append(opts, TextSortKeys())
+		}
+		if cfg.Encoding == "console" {
+			opts = append(opts, TextMultiline())
+		}
+		return NewTextEncoder(opts...), nil
+	default:
+		return nil, configError{"encoding", fmt.Errorf("unknown encoding %q", cfg.Encoding)}
+	}
+}
+
+func knownEncoding(name string) bool {
+	switch name {
+	case "json", "text", "console":
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *SamplingConfig) validate() error {
+	if s.Tick < 0 {
+		return configError{"sampling.tick", fmt.Errorf("must not be negative, got %v", s.Tick)}
+	}
+	if s.Initial < 0 {
+		return configError{"sampling.initial", fmt.Errorf("must not be negative, got %v", s.Initial)}
+	}
+	if s.Thereafter <= 0 {
+		return configError{"sampling.thereafter", fmt.Errorf("must be positive, got %v", s.Thereafter)}
+	}
+	return nil
+}
+
+// initialFields converts a map to Fields, sorted by key for stable output.
+func initialFields(m map[string]interface{}) []Field {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]Field, len(keys))
+	for i, k := range keys {
+		fields[i] = Any(k, m[k])
+	}
+	return fields
+}
+
+// openPaths opens each path for appending, creating files as necessary, and
+// combines them into a single WriteSyncer. "stdout" and "stderr" name the
+// standard streams. Like the defaults set by MakeMeta, each sink is wrapped
+// with a mutex, so Output and ErrorOutput don't lock them again. It also
+// returns a function that closes the opened files; if any path can't be
+// opened, the files opened so far are closed before returning.
+func openPaths(paths []string) (WriteSyncer, func() error, error) {
+	var (
+		sinks  []WriteSyncer
+		opened []*os.File
+	)
+	closeAll := func() error {
+		var errs multiError
+		for _, f := range opened {
+			if err := f.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errs.asError()
+	}
+	for _, path := range paths {
+		switch path {
+		case "stdout":
+			sinks = append(sinks, newLockedWriteSyncer(os.Stdout))
+			continue
+		case "stderr":
+			sinks = append(sinks, newLockedWriteSyncer(os.Stderr))
+			continue
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		opened = append(opened, f)
+		sinks = append(sinks, newLockedWriteSyncer(f))
+	}
+	if len(sinks) == 1 {
+		return sinks[0], closeAll, nil
+	}
+	return MultiWriteSyncer(sinks...), closeAll, nil
+}
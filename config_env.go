@@ -0,0 +1,186 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The environment variables recognized by Config.ApplyEnv.
+const (
+	_envPrefix          = "ZAP_"
+	_envLevel           = "ZAP_LEVEL"
+	_envNamedLevel      = "ZAP_LEVEL_"
+	_envEncoding        = "ZAP_ENCODING"
+	_envOutput          = "ZAP_OUTPUT"
+	_envErrorOutput     = "ZAP_ERROR_OUTPUT"
+	_envDevelopment     = "ZAP_DEVELOPMENT"
+	_envCaller          = "ZAP_CALLER"
+	_envStacktraceLevel = "ZAP_STACKTRACE_LEVEL"
+)
+
+// An envError reports a malformed environment variable.
+type envError struct {
+	name string
+	err  error
+}
+
+func (e envError) Error() string {
+	return fmt.Sprintf("invalid environment variable %s: %v", e.name, e.err)
+}
+
+// ApplyEnv overrides the Config with any ZAP_* variables in environ, which
+// is in the format returned by os.Environ. Since the environment is applied
+// on top of the Config, call ApplyEnv after setting up the Config in code and
+// before calling Build:
+//
+//   cfg := zap.Config{Encoding: "json"}
+//   if err := cfg.ApplyEnv(os.Environ()); err != nil {
+//     ...
+//   }
+//   logger, err := cfg.Build()
+//
+// The recognized variables are:
+//
+//   ZAP_LEVEL              the minimum enabled level (e.g., "debug")
+//   ZAP_LEVEL_<name>       the level of loggers with the given name (see Levels)
+//   ZAP_ENCODING           "json", "text", or "console"
+//   ZAP_OUTPUT             comma-separated output paths (e.g., "stderr")
+//   ZAP_ERROR_OUTPUT       comma-separated error output paths
+//   ZAP_DEVELOPMENT        a boolean, parsed with strconv.ParseBool
+//   ZAP_CALLER             a boolean, parsed with strconv.ParseBool
+//   ZAP_STACKTRACE_LEVEL   the minimum level that records stack traces
+//
+// Levels are parsed with Level.UnmarshalText, and ZAP_LEVEL replaces the
+// Config's AtomicLevel rather than changing it. Malformed values are reported
+// together in the returned error; the valid variables are still applied.
+// Other ZAP_* variables are ignored.
+func (cfg *Config) ApplyEnv(environ []string) error {
+	var errs multiError
+	vars := make(map[string]string)
+	for _, kv := range environ {
+		if !strings.HasPrefix(kv, _envPrefix) {
+			continue
+		}
+		idx := strings.IndexByte(kv, '=')
+		if idx < 0 {
+			continue
+		}
+		vars[kv[:idx]] = kv[idx+1:]
+	}
+
+	// Apply variables in a stable order, so that errors are reported
+	// deterministically.
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := cfg.applyEnvVar(name, vars[name]); err != nil {
+			errs = append(errs, envError{name, err})
+		}
+	}
+	return errs.asError()
+}
+
+func (cfg *Config) applyEnvVar(name, value string) error {
+	switch name {
+	case _envLevel:
+		// Configs are often copied from a preset, so rather than changing an
+		// AtomicLevel the copies may share, use a new one.
+		lvl := DynamicLevel()
+		if err := lvl.UnmarshalText([]byte(value)); err != nil {
+			return err
+		}
+		cfg.Level = lvl
+	case _envEncoding:
+		if !knownEncoding(value) {
+			return fmt.Errorf("unknown encoding %q", value)
+		}
+		cfg.Encoding = value
+	case _envOutput:
+		paths, err := splitPaths(value)
+		if err != nil {
+			return err
+		}
+		cfg.OutputPaths = paths
+	case _envErrorOutput:
+		paths, err := splitPaths(value)
+		if err != nil {
+			return err
+		}
+		cfg.ErrorOutputPaths = paths
+	case _envDevelopment:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		cfg.Development = b
+	case _envCaller:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		cfg.Caller = b
+	case _envStacktraceLevel:
+		var lvl Level
+		if err := lvl.UnmarshalText([]byte(value)); err != nil {
+			return err
+		}
+		cfg.StacktraceLevel = value
+	default:
+		if !strings.HasPrefix(name, _envNamedLevel) {
+			// Other programs use ZAP_ variables too (e.g., OWASP ZAP), so
+			// ignore names we don't recognize.
+			return nil
+		}
+		if len(name) == len(_envNamedLevel) {
+			return fmt.Errorf("missing logger name")
+		}
+		var lvl Level
+		if err := lvl.UnmarshalText([]byte(value)); err != nil {
+			return err
+		}
+		levels := make(map[string]Level, len(cfg.Levels)+1)
+		for k, v := range cfg.Levels {
+			levels[k] = v
+		}
+		levels[name[len(_envNamedLevel):]] = lvl
+		cfg.Levels = levels
+	}
+	return nil
+}
+
+func splitPaths(value string) ([]string, error) {
+	paths := strings.Split(value, ",")
+	for i, p := range paths {
+		paths[i] = strings.TrimSpace(p)
+		if paths[i] == "" {
+			return nil, fmt.Errorf("empty path in %q", value)
+		}
+	}
+	return paths, nil
+}
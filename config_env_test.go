@@ -0,0 +1,122 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigApplyEnv(t *testing.T) {
+	cfg := Config{
+		Encoding:    "json",
+		OutputPaths: []string{"stdout"},
+		Levels:      map[string]Level{"http": ErrorLevel},
+	}
+	shared := cfg.Levels
+	err := cfg.ApplyEnv([]string{
+		"HOME=/root",
+		"ZAP_LEVEL=debug",
+		"ZAP_LEVEL_db=warn",
+		"ZAP_LEVEL_db.pool=error",
+		"ZAP_ENCODING=console",
+		"ZAP_OUTPUT=stderr, /tmp/app.log",
+		"ZAP_ERROR_OUTPUT=stdout",
+		"ZAP_DEVELOPMENT=true",
+		"ZAP_CALLER=1",
+		"ZAP_STACKTRACE_LEVEL=warn",
+	})
+	require.NoError(t, err, "Unexpected error applying environment.")
+
+	assert.Equal(t, DebugLevel, cfg.Level.Level(), "Unexpected level.")
+	assert.Equal(t, map[string]Level{"http": ErrorLevel, "db": WarnLevel, "db.pool": ErrorLevel}, cfg.Levels, "Unexpected named levels.")
+	assert.Equal(t, map[string]Level{"http": ErrorLevel}, shared, "Expected the original Levels map to be left alone.")
+	assert.Equal(t, "console", cfg.Encoding, "Unexpected encoding.")
+	assert.Equal(t, []string{"stderr", "/tmp/app.log"}, cfg.OutputPaths, "Unexpected output paths.")
+	assert.Equal(t, []string{"stdout"}, cfg.ErrorOutputPaths, "Unexpected error output paths.")
+	assert.True(t, cfg.Development, "Expected development mode.")
+	assert.True(t, cfg.Caller, "Expected caller annotation.")
+	assert.Equal(t, "warn", cfg.StacktraceLevel, "Unexpected stacktrace level.")
+
+	original := Config{Level: DynamicLevel()}
+	copied := original
+	require.NoError(t, copied.ApplyEnv([]string{"ZAP_LEVEL=debug"}), "Unexpected error applying environment.")
+	assert.Equal(t, DebugLevel, copied.Level.Level(), "Unexpected level.")
+	assert.Equal(t, InfoLevel, original.Level.Level(), "Expected copies of a Config not to share the new level.")
+}
+
+func TestConfigApplyEnvErrors(t *testing.T) {
+	cfg := Config{Encoding: "json"}
+	err := cfg.ApplyEnv([]string{
+		"ZAP_LEVEL=loud",
+		"ZAP_LEVEL_db=WARN",
+		"ZAP_LEVEL_=info",
+		"ZAP_ENCODING=xml",
+		"ZAP_OUTPUT=stdout,",
+		"ZAP_DEVELOPMENT=sure",
+		"ZAP_STACKTRACE_LEVEL=often",
+		"ZAP_LEVLE=debug",
+		"ZAP_CALLER=false",
+	})
+	require.Error(t, err, "Expected malformed variables to be reported.")
+	for _, name := range []string{
+		"ZAP_LEVEL:", "ZAP_LEVEL_db:", "ZAP_LEVEL_:", "ZAP_ENCODING:", "ZAP_OUTPUT:",
+		"ZAP_DEVELOPMENT:", "ZAP_STACKTRACE_LEVEL:",
+	} {
+		assert.Contains(t, err.Error(), "invalid environment variable "+name, "Expected an error for %s.", name)
+	}
+	assert.NotContains(t, err.Error(), "ZAP_CALLER", "Unexpected error for a valid variable.")
+	assert.NotContains(t, err.Error(), "ZAP_LEVLE", "Unexpected error for an unrecognized variable.")
+	assert.Equal(t, "json", cfg.Encoding, "Expected malformed variables to be ignored.")
+	assert.Nil(t, cfg.Levels, "Expected malformed named levels to be ignored.")
+}
+
+func TestConfigBuildNamedLevels(t *testing.T) {
+	buf := &testBuffer{}
+	cfg := Config{EncoderConfig: EncoderConfig{TimeFormat: "none"}}
+	require.NoError(t, cfg.ApplyEnv([]string{"ZAP_LEVEL=warn", "ZAP_LEVEL_db=debug"}), "Unexpected error applying environment.")
+	logger, err := cfg.Build(Output(buf))
+	require.NoError(t, err, "Unexpected error building logger.")
+
+	logger.Info("root")
+	logger.Named("db").Debug("db")
+	logger.Named("db").Named("pool").Debug("pool")
+	logger.Named("http").Info("http")
+	assert.Equal(t, []string{
+		`{"level":"debug","logger":"db","msg":"db"}`,
+		`{"level":"debug","logger":"db.pool","msg":"pool"}`,
+	}, buf.Lines(), "Unexpected output with named levels.")
+
+	buf.Reset()
+	cfg.Level.SetLevel(InfoLevel)
+	logger.Named("http").Info("http")
+	logger.Named("db").Info("db")
+	assert.Equal(t, []string{
+		`{"level":"info","logger":"http","msg":"http"}`,
+		`{"level":"info","logger":"db","msg":"db"}`,
+	}, buf.Lines(), "Expected the Config's level to set the registry's default.")
+
+	cfg.Levels = map[string]Level{"[": InfoLevel}
+	_, err = cfg.Build()
+	assert.Contains(t, err.Error(), `"levels.["`, "Expected invalid patterns to be reported.")
+}
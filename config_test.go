@@ -0,0 +1,328 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/uber-go/zap/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func withTempDir(t testing.TB, f func(dir string)) {
+	dir, err := ioutil.TempDir("", "zap-config")
+	require.NoError(t, err, "Failed to create temporary directory.")
+	defer os.RemoveAll(dir)
+	f(dir)
+}
+
+func TestConfigUnmarshal(t *testing.T) {
+	const jsonConfig = `{
+		"level": "warn",
+		"development": true,
+		"encoding": "text",
+		"encoderConfig": {"messageKey": "message", "timeFormat": "none", "sortKeys": true},
+		"outputPaths": ["stdout", "/tmp/app.log"],
+		"errorOutputPaths": ["stderr"],
+		"sampling": {"tick": 1000000000, "initial": 10, "thereafter": 5},
+		"caller": true,
+		"stacktraceLevel": "error",
+		"initialFields": {"service": "app"}
+	}`
+	const yamlConfig = `
+level: warn
+development: true
+encoding: text
+encoderConfig:
+  messageKey: message
+  timeFormat: none
+  sortKeys: true
+outputPaths: [stdout, /tmp/app.log]
+errorOutputPaths: [stderr]
+sampling:
+  tick: 1s
+  initial: 10
+  thereafter: 5
+caller: true
+stacktraceLevel: error
+initialFields:
+  service: app
+`
+
+	check := func(cfg Config, format string) {
+		assert.Equal(t, WarnLevel, cfg.Level.Level(), "Unexpected level from %s.", format)
+		assert.True(t, cfg.Development, "Expected development mode from %s.", format)
+		assert.Equal(t, "text", cfg.Encoding, "Unexpected encoding from %s.", format)
+		assert.Equal(t, EncoderConfig{MessageKey: "message", TimeFormat: "none", SortKeys: true}, cfg.EncoderConfig, "Unexpected encoder config from %s.", format)
+		assert.Equal(t, []string{"stdout", "/tmp/app.log"}, cfg.OutputPaths, "Unexpected output paths from %s.", format)
+		assert.Equal(t, []string{"stderr"}, cfg.ErrorOutputPaths, "Unexpected error output paths from %s.", format)
+		assert.Equal(t, &SamplingConfig{Tick: time.Second, Initial: 10, Thereafter: 5}, cfg.Sampling, "Unexpected sampling config from %s.", format)
+		assert.True(t, cfg.Caller, "Expected caller from %s.", format)
+		assert.Equal(t, "error", cfg.StacktraceLevel, "Unexpected stacktrace level from %s.", format)
+		assert.Equal(t, map[string]interface{}{"service": "app"}, cfg.InitialFields, "Unexpected initial fields from %s.", format)
+	}
+
+	var fromJSON Config
+	require.NoError(t, json.Unmarshal([]byte(jsonConfig), &fromJSON), "Failed to unmarshal JSON config.")
+	check(fromJSON, "JSON")
+
+	var fromYAML Config
+	require.NoError(t, yaml.Unmarshal([]byte(yamlConfig), &fromYAML), "Failed to unmarshal YAML config.")
+	check(fromYAML, "YAML")
+
+	var bad Config
+	assert.Error(t, json.Unmarshal([]byte(`{"level": "loud"}`), &bad), "Expected an error unmarshaling an invalid level.")
+
+	var stringTick Config
+	require.NoError(t, json.Unmarshal([]byte(`{"sampling": {"tick": "1s", "thereafter": 5}}`), &stringTick), "Failed to unmarshal a duration string from JSON.")
+	assert.Equal(t, &SamplingConfig{Tick: time.Second, Thereafter: 5}, stringTick.Sampling, "Unexpected sampling config with a duration string.")
+}
+
+func TestConfigUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		json string
+		yaml string
+		key  string
+	}{
+		{`{"level": "loud"}`, `level: loud`, "level"},
+		{`{"levels": {"db": "loud"}}`, "levels:\n  db: loud", "levels.db"},
+		{`{"encoding": "xml"}`, "encoding: xml", "encoding"},
+		{`{"stacktraceLevel": "loud"}`, "stacktraceLevel: loud", "stacktraceLevel"},
+		{`{"sampling": "often"}`, "", "sampling"},
+		{`{"sampling": {"tick": "soon"}}`, "", "sampling.tick"},
+		{`{"sampling": {"tick": true}}`, "", "sampling.tick"},
+		{`{"sampling": {"initial": "x"}}`, "", "sampling.initial"},
+		{`{"sampling": {"Thereafter": 1.5}}`, "", "sampling.thereafter"},
+	}
+	for _, tt := range tests {
+		var cfg Config
+		err := json.Unmarshal([]byte(tt.json), &cfg)
+		if assert.Error(t, err, "Expected an error unmarshaling %s.", tt.json) {
+			assert.Contains(t, err.Error(), `"`+tt.key+`"`, "Expected the error to name the offending key.")
+		}
+		if tt.yaml == "" {
+			continue
+		}
+		err = yaml.Unmarshal([]byte(tt.yaml), &cfg)
+		if assert.Error(t, err, "Expected an error unmarshaling %s.", tt.yaml) {
+			assert.Contains(t, err.Error(), `"`+tt.key+`"`, "Expected the error to name the offending key.")
+		}
+	}
+}
+
+func TestConfigZeroRoundTrip(t *testing.T) {
+	data, err := json.Marshal(Config{})
+	require.NoError(t, err, "Unexpected error marshaling a zero Config.")
+
+	var cfg Config
+	require.NoError(t, json.Unmarshal(data, &cfg), "Unexpected error unmarshaling %s.", data)
+	assert.Equal(t, InfoLevel, cfg.Level.Level(), "Expected a zero level to round-trip as InfoLevel.")
+	cfg.Level = AtomicLevel{}
+	assert.Equal(t, Config{}, cfg, "Expected the rest of a zero Config to round-trip.")
+}
+
+func TestConfigBuild(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "app.log")
+		cfg := Config{
+			Level:         DynamicLevel(),
+			EncoderConfig: EncoderConfig{MessageKey: "message", TimeFormat: "none"},
+			OutputPaths:   []string{path},
+			Caller:        true,
+			InitialFields: map[string]interface{}{"service": "app", "instance": 1},
+		}
+		logger, err := cfg.Build(Fields(String("extra", "option")))
+		require.NoError(t, err, "Unexpected error building logger.")
+
+		logger.Debug("disabled")
+		cfg.Level.SetLevel(DebugLevel)
+		logger.Debug("enabled")
+		require.NoError(t, logger.Sync(), "Unexpected error syncing.")
+
+		contents, err := ioutil.ReadFile(path)
+		require.NoError(t, err, "Failed to read log file.")
+		assert.Regexp(
+			t,
+			`^\{"level":"debug","message":"enabled","instance":1,"service":"app","extra":"option","caller":"config_test.go:\d+"\}\n$`,
+			string(contents),
+			"Unexpected log file contents.",
+		)
+	})
+}
+
+func TestConfigBuildEncodings(t *testing.T) {
+	tests := []struct {
+		cfg      Config
+		expected string
+	}{
+		{Config{}, `{"level":"info","ts":0,"msg":"hello","k":"v"}`},
+		{Config{EncoderConfig: EncoderConfig{TimeKey: "time", LevelKey: "severity"}}, `{"severity":"info","time":0,"msg":"hello","k":"v"}`},
+		{Config{EncoderConfig: EncoderConfig{TimeFormat: "rfc3339"}}, `{"level":"info","ts":"1970-01-01T00:00:00Z","msg":"hello","k":"v"}`},
+		{Config{Encoding: "text", EncoderConfig: EncoderConfig{TimeFormat: "none"}}, `[I] hello k=v`},
+		{Config{Encoding: "console", EncoderConfig: EncoderConfig{TimeFormat: "rfc3339"}}, `[I] 1970-01-01T00:00:00Z hello k=v`},
+	}
+	for _, tt := range tests {
+		buf := &testBuffer{}
+		logger, err := tt.cfg.Build(Output(buf), WithClock(testutils.NewFakeClock(time.Unix(0, 0))))
+		require.NoError(t, err, "Unexpected error building logger from %+v.", tt.cfg)
+		logger.Info("hello", String("k", "v"))
+		assert.Equal(t, tt.expected, buf.Stripped(), "Unexpected output from %+v.", tt.cfg)
+	}
+}
+
+func TestConfigBuildSampling(t *testing.T) {
+	buf := &testBuffer{}
+	cfg := Config{Sampling: &SamplingConfig{Initial: 2, Thereafter: 100}}
+	logger, err := cfg.Build(Output(buf))
+	require.NoError(t, err, "Unexpected error building logger.")
+	for i := 0; i < 5; i++ {
+		logger.Info("sampled")
+	}
+	assert.Equal(t, 2, len(buf.Lines()), "Expected sampling to drop entries.")
+}
+
+func TestConfigBuildErrors(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		missing := filepath.Join(dir, "missing", "app.log")
+		tests := []struct {
+			cfg Config
+			key string
+		}{
+			{Config{Encoding: "xml"}, "encoding"},
+			{Config{EncoderConfig: EncoderConfig{TimeFormat: "sundial"}}, "encoderConfig.timeFormat"},
+			{Config{Encoding: "text", EncoderConfig: EncoderConfig{TimeFormat: "epoch"}}, "encoderConfig.timeFormat"},
+			{Config{Sampling: &SamplingConfig{Tick: -1, Thereafter: 1}}, "sampling.tick"},
+			{Config{Sampling: &SamplingConfig{Initial: -1, Thereafter: 1}}, "sampling.initial"},
+			{Config{Sampling: &SamplingConfig{}}, "sampling.thereafter"},
+			{Config{StacktraceLevel: "loud"}, "stacktraceLevel"},
+			{Config{OutputPaths: []string{"stdout", missing}}, "outputPaths"},
+			{Config{ErrorOutputPaths: []string{missing}}, "errorOutputPaths"},
+		}
+		for _, tt := range tests {
+			_, err := tt.cfg.Build()
+			if assert.Error(t, err, "Expected an error building %+v.", tt.cfg) {
+				assert.Contains(t, err.Error(), `"`+tt.key+`"`, "Expected the error to name the offending key.")
+			}
+		}
+	})
+}
+
+func TestOpenPaths(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "app.log")
+		out, closeFiles, err := openPaths([]string{path})
+		require.NoError(t, err, "Unexpected error opening a file.")
+		assert.IsType(t, &lockedWriteSyncer{}, out, "Expected sinks to be wrapped with a mutex.")
+		assert.True(t, out == newLockedWriteSyncer(out), "Expected a locked sink not to be locked again.")
+		_, err = out.Write([]byte("foo\n"))
+		assert.NoError(t, err, "Unexpected error writing to an open file.")
+
+		assert.NoError(t, closeFiles(), "Unexpected error closing files.")
+		_, err = out.Write([]byte("bar\n"))
+		assert.Error(t, err, "Expected an error writing to a closed file.")
+
+		out, _, err = openPaths([]string{"stdout", "stderr"})
+		require.NoError(t, err, "Unexpected error opening the standard streams.")
+		assert.IsType(t, multiWriteSyncer{}, out, "Expected a combined WriteSyncer.")
+		assert.Equal(t, out, newLockedWriteSyncer(out), "Expected combined locked sinks not to be locked again.")
+	})
+}
+
+func TestConfigOpen(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "app.log")
+		cfg := Config{
+			EncoderConfig:    EncoderConfig{TimeFormat: "none"},
+			OutputPaths:      []string{path},
+			ErrorOutputPaths: []string{path},
+		}
+		logger, closeFiles, err := cfg.Open()
+		require.NoError(t, err, "Unexpected error opening a logger.")
+		logger.Info("open")
+		require.NoError(t, closeFiles(), "Unexpected error closing the logger's files.")
+		assert.Error(t, closeFiles(), "Expected an error closing the files twice.")
+
+		contents, err := ioutil.ReadFile(path)
+		require.NoError(t, err, "Failed to read the log file.")
+		assert.Equal(t, `{"level":"info","msg":"open"}`+"\n", string(contents), "Unexpected log file contents.")
+	})
+}
+
+func TestNewProduction(t *testing.T) {
+	buf := &testBuffer{}
+	logger, err := NewProduction(Output(buf), WithClock(testutils.NewFakeClock(time.Unix(0, 0))))
+	require.NoError(t, err, "Unexpected error building production logger.")
+
+	logger.Debug("disabled")
+	logger.Info("info")
+	assert.Regexp(
+		t,
+		`^\{"level":"info","ts":"1970-01-01T00:00:00.000Z","msg":"info","caller":"config_test.go:\d+"\}$`,
+		buf.Stripped(),
+		"Unexpected production output.",
+	)
+
+	buf.Reset()
+	logger.Error("error")
+	assert.Contains(t, buf.String(), `"stacktrace":`, "Expected stack traces at ErrorLevel.")
+
+	buf.Reset()
+	for i := 0; i < 150; i++ {
+		logger.Info("sampled")
+	}
+	assert.Equal(t, 100, len(buf.Lines()), "Expected production logger to sample.")
+
+	assert.NotPanics(t, func() { logger.DPanic("dpanic") }, "Expected DPanic not to panic in production.")
+}
+
+func TestNewDevelopment(t *testing.T) {
+	buf := &testBuffer{}
+	logger, err := NewDevelopment(Output(buf), WithClock(testutils.NewFakeClock(time.Unix(0, 0))))
+	require.NoError(t, err, "Unexpected error building development logger.")
+
+	logger.Debug("debug")
+	assert.Equal(t, "[D] 1970-01-01T00:00:00.000Z debug", buf.Stripped(), "Unexpected development output.")
+
+	buf.Reset()
+	logger.Info("no stacks")
+	assert.NotContains(t, buf.String(), "stacktrace", "Unexpected stack trace below WarnLevel.")
+	logger.Warn("stacks")
+	assert.Contains(t, buf.String(), "stacktrace", "Expected stack traces at WarnLevel.")
+
+	assert.Panics(t, func() { logger.DPanic("dpanic") }, "Expected DPanic to panic in development.")
+}
+
+func TestPresetsReportOutputErrors(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		cfg := NewProductionConfig()
+		cfg.OutputPaths = []string{filepath.Join(dir, "missing", "app.log")}
+		_, err := cfg.Build()
+		assert.Error(t, err, "Expected an error opening a missing directory.")
+	})
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// +build go1.7
+
+package zap
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+type contextKey int
+
+const (
+	_loggerKey contextKey = iota
+	_fieldsKey
+)
+
+// A ContextExtractor pulls request-scoped values, such as request IDs or
+// deadlines, out of a context.Context. It appends any fields it finds to the
+// supplied slice and returns the result.
+type ContextExtractor func(ctx context.Context, fields []Field) []Field
+
+var (
+	_contextMu         sync.Mutex // serializes writers of _contextExtractors
+	_contextExtractors atomic.Value
+)
+
+// RegisterContextExtractors adds extractors that run whenever a logger or an
+// entry draws fields from a context (see FromContext and the *Ctx functions).
+// It returns a function that removes them again.
+func RegisterContextExtractors(extractors ...ContextExtractor) func() {
+	_contextMu.Lock()
+	prev, _ := _contextExtractors.Load().([]ContextExtractor)
+	all := make([]ContextExtractor, 0, len(prev)+len(extractors))
+	all = append(all, prev...)
+	all = append(all, extractors...)
+	_contextExtractors.Store(all)
+	_contextMu.Unlock()
+	return func() {
+		_contextMu.Lock()
+		_contextExtractors.Store(prev)
+		_contextMu.Unlock()
+	}
+}
+
+// ContextValue returns a ContextExtractor that adds the value stored under
+// ctxKey, if any, as a field with the given key.
+func ContextValue(ctxKey interface{}, fieldKey string) ContextExtractor {
+	return func(ctx context.Context, fields []Field) []Field {
+		if val := ctx.Value(ctxKey); val != nil {
+			fields = append(fields, Any(fieldKey, val))
+		}
+		return fields
+	}
+}
+
+// ContextDeadline returns a ContextExtractor that adds the context's deadline,
+// if it has one, as a Time field with the given key.
+func ContextDeadline(fieldKey string) ContextExtractor {
+	return func(ctx context.Context, fields []Field) []Field {
+		if deadline, ok := ctx.Deadline(); ok {
+			fields = append(fields, Time(fieldKey, deadline))
+		}
+		return fields
+	}
+}
+
+// NewContext returns a copy of ctx that carries the given Logger.
+func NewContext(ctx context.Context, log Logger) context.Context {
+	return context.WithValue(ctx, _loggerKey, log)
+}
+
+// WithContextFields returns a copy of ctx that carries the given fields in
+// addition to any it already carries. They're added to entries logged with
+// the *Ctx functions and to loggers returned by FromContext.
+func WithContextFields(ctx context.Context, fields ...Field) context.Context {
+	prev, _ := ctx.Value(_fieldsKey).([]Field)
+	all := make([]Field, 0, len(prev)+len(fields))
+	all = append(all, prev...)
+	all = append(all, fields...)
+	return context.WithValue(ctx, _fieldsKey, all)
+}
+
+// FromContext returns the Logger carried by ctx, or the process-global Logger
+// if there isn't one (see L and ReplaceGlobals). If the context also carries
+// fields or any registered extractors find values in it, they're added to the
+// returned Logger's context.
+func FromContext(ctx context.Context) Logger {
+	log := contextLogger(ctx)
+	if fields := contextFields(ctx, nil); len(fields) > 0 {
+		return log.With(fields...)
+	}
+	return log
+}
+
+// DebugCtx logs at DebugLevel, using the Logger and fields carried by ctx.
+func DebugCtx(ctx context.Context, msg string, fields ...Field) {
+	logCtx(ctx, DebugLevel, msg, fields)
+}
+
+// InfoCtx logs at InfoLevel, using the Logger and fields carried by ctx.
+func InfoCtx(ctx context.Context, msg string, fields ...Field) {
+	logCtx(ctx, InfoLevel, msg, fields)
+}
+
+// WarnCtx logs at WarnLevel, using the Logger and fields carried by ctx.
+func WarnCtx(ctx context.Context, msg string, fields ...Field) {
+	logCtx(ctx, WarnLevel, msg, fields)
+}
+
+// ErrorCtx logs at ErrorLevel, using the Logger and fields carried by ctx.
+func ErrorCtx(ctx context.Context, msg string, fields ...Field) {
+	logCtx(ctx, ErrorLevel, msg, fields)
+}
+
+// DPanicCtx logs at DPanicLevel, using the Logger and fields carried by ctx.
+// In development, the logger then panics.
+func DPanicCtx(ctx context.Context, msg string, fields ...Field) {
+	logCtx(ctx, DPanicLevel, msg, fields)
+}
+
+// PanicCtx logs at PanicLevel, using the Logger and fields carried by ctx,
+// then panics.
+func PanicCtx(ctx context.Context, msg string, fields ...Field) {
+	logCtx(ctx, PanicLevel, msg, fields)
+}
+
+// FatalCtx logs at FatalLevel, using the Logger and fields carried by ctx,
+// then calls os.Exit.
+func FatalCtx(ctx context.Context, msg string, fields ...Field) {
+	logCtx(ctx, FatalLevel, msg, fields)
+}
+
+func logCtx(ctx context.Context, lvl Level, msg string, fields []Field) {
+	// Only gather the context's fields if the message will be written.
+	if cm := contextLogger(ctx).Check(lvl, msg); cm.OK() {
+		cm.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func contextLogger(ctx context.Context) Logger {
+	if log, ok := ctx.Value(_loggerKey).(Logger); ok {
+		return log
+	}
+	return L()
+}
+
+// contextFields returns the fields carried by ctx, then those found by the
+// registered extractors, then the supplied fields.
+func contextFields(ctx context.Context, fields []Field) []Field {
+	carried, _ := ctx.Value(_fieldsKey).([]Field)
+	extractors, _ := _contextExtractors.Load().([]ContextExtractor)
+	if len(carried) == 0 && len(extractors) == 0 {
+		return fields
+	}
+	all := make([]Field, 0, len(carried)+len(extractors)+len(fields))
+	all = append(all, carried...)
+	for _, extract := range extractors {
+		all = extract(ctx, all)
+	}
+	return append(all, fields...)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// +build go1.7
+
+package zap_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIDKey struct{}
+
+func TestContextLogger(t *testing.T) {
+	log, sink := spy.New(zap.DebugLevel)
+	ctx := zap.NewContext(context.Background(), log)
+	ctx = zap.WithContextFields(ctx, zap.String("user", "alice"))
+	child := zap.WithContextFields(ctx, zap.Int("attempt", 2))
+
+	zap.InfoCtx(child, "ctx", zap.Bool("ok", true))
+	zap.FromContext(ctx).Warn("from")
+	zap.DebugCtx(context.Background(), "default")
+
+	assert.Equal(t, []spy.Log{
+		{
+			Level:  zap.InfoLevel,
+			Msg:    "ctx",
+			Fields: []zap.Field{zap.String("user", "alice"), zap.Int("attempt", 2), zap.Bool("ok", true)},
+		},
+		{Level: zap.WarnLevel, Msg: "from", Fields: []zap.Field{zap.String("user", "alice")}},
+	}, sink.Logs(), "Unexpected output from context-scoped logging.")
+}
+
+func TestContextGlobalLogger(t *testing.T) {
+	log, sink := spy.New(zap.DebugLevel)
+	restore := zap.ReplaceGlobals(log)
+
+	zap.ErrorCtx(context.Background(), "default")
+	zap.FromContext(context.Background()).Info("fetched")
+	restore()
+	zap.DebugCtx(context.Background(), "restored")
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.ErrorLevel, Msg: "default", Fields: []zap.Field{}},
+		{Level: zap.InfoLevel, Msg: "fetched", Fields: []zap.Field{}},
+	}, sink.Logs(), "Expected to fall back to the global logger.")
+}
+
+func TestContextExtractors(t *testing.T) {
+	log, sink := spy.New(zap.DebugLevel)
+	undo := zap.RegisterContextExtractors(
+		zap.ContextValue(requestIDKey{}, "requestID"),
+		zap.ContextDeadline("deadline"),
+	)
+
+	deadline := time.Unix(100, 0)
+	ctx := zap.NewContext(context.Background(), log)
+	zap.InfoCtx(ctx, "no values")
+
+	ctx = context.WithValue(ctx, requestIDKey{}, "abc")
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	zap.WarnCtx(ctx, "values", zap.Int("n", 1))
+
+	undo()
+	zap.ErrorCtx(ctx, "unregistered")
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.InfoLevel, Msg: "no values", Fields: []zap.Field{}},
+		{
+			Level:  zap.WarnLevel,
+			Msg:    "values",
+			Fields: []zap.Field{zap.String("requestID", "abc"), zap.Time("deadline", deadline), zap.Int("n", 1)},
+		},
+		{Level: zap.ErrorLevel, Msg: "unregistered", Fields: []zap.Field{}},
+	}, sink.Logs(), "Expected registered extractors to add fields.")
+}
+
+func TestContextDisabledLevelsSkipExtraction(t *testing.T) {
+	log, sink := spy.New(zap.ErrorLevel)
+	calls := 0
+	undo := zap.RegisterContextExtractors(func(ctx context.Context, fields []zap.Field) []zap.Field {
+		calls++
+		return fields
+	})
+	defer undo()
+
+	zap.InfoCtx(zap.NewContext(context.Background(), log), "disabled")
+	assert.Equal(t, 0, calls, "Expected disabled levels not to run extractors.")
+	assert.Empty(t, sink.Logs(), "Expected disabled levels to be dropped.")
+}
+
+func TestContextPanics(t *testing.T) {
+	log := zap.New(zap.NewJSONEncoder(), zap.Output(zap.AddSync(discard{})), zap.Development())
+	ctx := zap.NewContext(context.Background(), log)
+	assert.Panics(t, func() { zap.PanicCtx(ctx, "panic") }, "Expected PanicCtx to panic.")
+	assert.Panics(t, func() { zap.DPanicCtx(ctx, "dpanic") }, "Expected DPanicCtx to panic in development.")
+}
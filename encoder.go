@@ -21,6 +21,7 @@
 package zap
 
 import (
+	"fmt"
 	"io"
 	"time"
 )
@@ -46,3 +47,41 @@ type Encoder interface {
 	// any accumulated context.
 	WriteEntry(io.Writer, string, Level, time.Time) error
 }
+
+// nameSetter is implemented by encoders that include the logger's name in
+// each entry. Meta.Named uses it to pass the name along.
+type nameSetter interface {
+	setName(string)
+}
+
+// A writeError wraps an error returned by an encoder's sink, so that internal
+// error reporting can tell write failures from encoding failures.
+type writeError struct {
+	err error
+}
+
+func (e writeError) Error() string {
+	return e.err.Error()
+}
+
+// A shortWriteError reports that a sink accepted only part of an entry.
+type shortWriteError struct {
+	n, expected int
+}
+
+func (e shortWriteError) Error() string {
+	return fmt.Sprintf("incomplete write: only wrote %v of %v bytes", e.n, e.expected)
+}
+
+// writeEntry writes a fully-encoded entry to the sink. Encoders should use it
+// to report write failures consistently.
+func writeEntry(sink io.Writer, bs []byte) error {
+	n, err := sink.Write(bs)
+	if err != nil {
+		return writeError{err}
+	}
+	if n != len(bs) {
+		return shortWriteError{n: n, expected: len(bs)}
+	}
+	return nil
+}
@@ -22,9 +22,10 @@ package zap
 
 import "time"
 
-// An Entry represents a complete log message. The entry's structured context
-// is already serialized, but the log level, time, and message are available
-// for inspection and modification.
+// An Entry represents a complete log message. The logger's accumulated
+// context is already serialized, but the log level, time, message, and the
+// fields passed at the call site are available for inspection and
+// modification.
 //
 // Entries are pooled, so any functions that accept them must be careful not to
 // retain references to them.
@@ -32,10 +33,29 @@ type Entry struct {
 	Level   Level
 	Time    time.Time
 	Message string
-	enc     Encoder
+	// Fields holds the entry's call-site fields. Hooks may read, edit, append
+	// to, or remove from this slice; it's encoded after all hooks have run.
+	Fields  []Field
+	dropped bool
 }
 
-// Fields returns a mutable reference to the entry's accumulated context.
-func (e Entry) Fields() KeyValue {
-	return e.enc
+// Drop discards the entry: no further hooks run, and nothing is written.
+func (e *Entry) Drop() {
+	e.dropped = true
+}
+
+// Dropped reports whether a hook has discarded the entry.
+func (e *Entry) Dropped() bool {
+	return e.dropped
+}
+
+// reset prepares a pooled entry for re-use, releasing references to the
+// previous entry's fields.
+func (e *Entry) reset() {
+	for i := range e.Fields {
+		e.Fields[i] = Field{}
+	}
+	e.Fields = e.Fields[:0]
+	e.Message = ""
+	e.dropped = false
 }
@@ -0,0 +1,130 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"time"
+)
+
+// _defaultShutdownTimeout bounds the time Fatal waits for shutdown hooks.
+const _defaultShutdownTimeout = 5 * time.Second
+
+// Exit ends the process on behalf of Fatal. It calls Shutdown, and then calls
+// the exit function (os.Exit by default) with the configured exit code.
+func (m Meta) Exit() {
+	exit, code := m.Shutdown()
+	if exit == nil {
+		exit = _exit
+	}
+	exit(code)
+}
+
+// Shutdown prepares the process to exit: it syncs the outputs and runs the
+// shutdown hooks (see OnShutdown) within the shutdown timeout. Shutdown hooks
+// that panic or time out are reported as internal errors with the cause
+// "shutdown". It returns the function set by the ExitFunc option (nil if there
+// isn't one) and the exit code, so that loggers that fan out to others can
+// shut all of them down before choosing how to exit.
+func (m Meta) Shutdown() (exit func(int), code int) {
+	m.Output.Sync()
+	if len(m.ShutdownHooks) > 0 {
+		m.runShutdownHooks()
+		m.ErrorOutput.Sync()
+	}
+	return m.ExitFunc, m.ExitCode
+}
+
+func (m Meta) runShutdownHooks() {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, hook := range m.ShutdownHooks {
+			m.runShutdownHook(hook)
+		}
+	}()
+
+	if m.ShutdownTimeout <= 0 {
+		<-done
+		return
+	}
+	timer := time.NewTimer(m.ShutdownTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		m.InternalError(shutdownCause, fmt.Errorf("shutdown hooks didn't finish within %v", m.ShutdownTimeout))
+	}
+}
+
+func (m Meta) runShutdownHook(hook func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			m.InternalError(shutdownCause, fmt.Errorf("shutdown hook panicked: %v", r))
+		}
+	}()
+	hook()
+}
+
+// shutdowner is implemented by loggers that embed a Meta, and by loggers that
+// wrap or fan out to others (like Tee, Sample, and zwrap.Filter) so that
+// Fatal reaches the Metas they wrap.
+type shutdowner interface {
+	Shutdown() (exit func(int), code int)
+}
+
+// shutdownAll syncs every logger, then shuts down those that implement
+// shutdowner. It returns the first exit function set by any of them, along
+// with that logger's exit code; if none is set, it returns the exit code of
+// the first logger that shut down, or 1 if none did.
+func shutdownAll(logs []Logger) (exit func(int), code int) {
+	for _, log := range logs {
+		log.Sync()
+	}
+	found := false
+	for _, log := range logs {
+		s, ok := log.(shutdowner)
+		if !ok {
+			continue
+		}
+		e, c := s.Shutdown()
+		if exit == nil && e != nil {
+			exit, code, found = e, c, true
+		} else if !found {
+			code, found = c, true
+		}
+	}
+	if !found {
+		code = 1
+	}
+	return exit, code
+}
+
+// exitVia ends the process on behalf of a logger that fans out to others,
+// once they've all received a Fatal entry. It shuts all of them down, so that
+// every shutdown hook runs, and then exits as chosen by shutdownAll.
+func exitVia(logs []Logger) {
+	exit, code := shutdownAll(logs)
+	if exit == nil {
+		exit = _exit
+	}
+	exit(code)
+}
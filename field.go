@@ -131,7 +131,10 @@ func Time(key string, val time.Time) Field {
 }
 
 // Error constructs a Field that lazily stores err.Error() under the key
-// "error". If passed a nil error, the field is a no-op.
+// "error". When the field is encoded by a text encoder with TextMultiline set,
+// errors that implement fmt.Formatter and have verbose ("%+v") output that
+// differs from err.Error() also store that output under the key
+// "errorVerbose". If passed a nil error, the field is a no-op.
 func Error(err error) Field {
 	if err == nil {
 		return Skip()
@@ -182,12 +185,78 @@ func Object(key string, val interface{}) Field {
 	return Field{key: key, fieldType: objectType, obj: val}
 }
 
+// Any takes a key and an arbitrary value and chooses the best way to represent
+// them as a field, falling back to a reflection-based approach (see Object)
+// only if necessary.
+func Any(key string, value interface{}) Field {
+	switch val := value.(type) {
+	case bool:
+		return Bool(key, val)
+	case float64:
+		return Float64(key, val)
+	case float32:
+		return Float64(key, float64(val))
+	case int:
+		return Int(key, val)
+	case int64:
+		return Int64(key, val)
+	case int32:
+		return Int64(key, int64(val))
+	case int16:
+		return Int64(key, int64(val))
+	case int8:
+		return Int64(key, int64(val))
+	case uint:
+		return Uint(key, val)
+	case uint64:
+		return Uint64(key, val)
+	case uint32:
+		return Uint64(key, uint64(val))
+	case uint16:
+		return Uint64(key, uint64(val))
+	case uint8:
+		return Uint64(key, uint64(val))
+	case uintptr:
+		return Uintptr(key, val)
+	case string:
+		return String(key, val)
+	case time.Time:
+		return Time(key, val)
+	case time.Duration:
+		return Duration(key, val)
+	case LogMarshaler:
+		return Marshaler(key, val)
+	case error:
+		return Field{key: key, fieldType: errorType, obj: val}
+	case fmt.Stringer:
+		return Stringer(key, val)
+	default:
+		return Object(key, val)
+	}
+}
+
 // Nest takes a key and a variadic number of Fields and creates a nested
 // namespace.
 func Nest(key string, fields ...Field) Field {
 	return Field{key: key, fieldType: marshalerType, obj: multiFields(fields)}
 }
 
+// Key returns the field's key.
+func (f Field) Key() string {
+	return f.key
+}
+
+// Value returns the field's value, as it would be added to a KeyValue: a
+// bool, float64, int, int64, uint, uint64, uintptr, string, LogMarshaler, or
+// arbitrary object. Errors and Stringers are returned as strings, and the
+// value of a Skip field is nil. It's primarily useful to library authors that
+// route or filter entries based on their fields.
+func (f Field) Value() interface{} {
+	var c valueCapture
+	f.AddTo(&c)
+	return c.value
+}
+
 // AddTo exports a field through the KeyValue interface. It's primarily useful
 // to library authors, and shouldn't be necessary in most applications.
 func (f Field) AddTo(kv KeyValue) {
@@ -217,7 +286,7 @@ func (f Field) AddTo(kv KeyValue) {
 	case objectType:
 		err = kv.AddObject(f.key, f.obj)
 	case errorType:
-		kv.AddString(f.key, f.obj.(error).Error())
+		addError(kv, f.key, f.obj.(error))
 	case skipType:
 		break
 	default:
@@ -229,6 +298,30 @@ func (f Field) AddTo(kv KeyValue) {
 	}
 }
 
+// verboseErrorEncoder is implemented by encoders that may want to render
+// verbose error output in addition to the error message.
+type verboseErrorEncoder interface {
+	verboseErrors() bool
+}
+
+// addError adds the error's message under key. Errors that implement
+// fmt.Formatter may carry more detail, such as a stacktrace, in their "%+v"
+// output; if the encoder wants it and it differs from the message, it's added
+// under key+"Verbose".
+func addError(kv KeyValue, key string, err error) {
+	basic := err.Error()
+	kv.AddString(key, basic)
+	if v, ok := kv.(verboseErrorEncoder); !ok || !v.verboseErrors() {
+		return
+	}
+	if _, ok := err.(fmt.Formatter); !ok {
+		return
+	}
+	if verbose := fmt.Sprintf("%+v", err); verbose != basic {
+		kv.AddString(key+"Verbose", verbose)
+	}
+}
+
 type multiFields []Field
 
 func (fs multiFields) MarshalLog(kv KeyValue) error {
@@ -241,3 +334,27 @@ func addFields(kv KeyValue, fields []Field) {
 		f.AddTo(kv)
 	}
 }
+
+// valueCapture is a KeyValue that remembers the last value added to it.
+type valueCapture struct {
+	value interface{}
+}
+
+func (c *valueCapture) AddBool(_ string, v bool)       { c.value = v }
+func (c *valueCapture) AddFloat64(_ string, v float64) { c.value = v }
+func (c *valueCapture) AddInt(_ string, v int)         { c.value = v }
+func (c *valueCapture) AddInt64(_ string, v int64)     { c.value = v }
+func (c *valueCapture) AddUint(_ string, v uint)       { c.value = v }
+func (c *valueCapture) AddUint64(_ string, v uint64)   { c.value = v }
+func (c *valueCapture) AddUintptr(_ string, v uintptr) { c.value = v }
+func (c *valueCapture) AddString(_, v string)          { c.value = v }
+
+func (c *valueCapture) AddMarshaler(_ string, v LogMarshaler) error {
+	c.value = v
+	return nil
+}
+
+func (c *valueCapture) AddObject(_ string, v interface{}) error {
+	c.value = v
+	return nil
+}
@@ -23,6 +23,8 @@ package zap
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net"
 	"strings"
 	"sync"
@@ -158,6 +160,49 @@ func TestErrField(t *testing.T) {
 	assertFieldJSON(t, `"error":"fail"`, Error(errors.New("fail")))
 	assertFieldJSON(t, ``, Error(nil))
 	assertCanBeReused(t, Error(errors.New("fail")))
+
+	verbose := verboseError{msg: "fail", detail: "fail\nat main.go:10"}
+	assertFieldJSON(t, `"error":"fail"`, Error(verbose))
+	assertCanBeReused(t, Error(verbose))
+}
+
+// verboseError mimics errors that attach extra detail to their "%+v" output.
+type verboseError struct{ msg, detail string }
+
+func (e verboseError) Error() string { return e.msg }
+
+func (e verboseError) Format(s fmt.State, verb rune) {
+	if s.Flag('+') {
+		io.WriteString(s, e.detail)
+		return
+	}
+	io.WriteString(s, e.msg)
+}
+
+func TestAnyField(t *testing.T) {
+	now := time.Unix(1, 0)
+	err := errors.New("fail")
+	tests := []struct {
+		value    interface{}
+		expected Field
+	}{
+		{true, Bool("k", true)},
+		{float32(1.5), Float64("k", 1.5)},
+		{int8(-1), Int64("k", -1)},
+		{uint16(1), Uint64("k", 1)},
+		{uintptr(0xa), Uintptr("k", 0xa)},
+		{"v", String("k", "v")},
+		{now, Time("k", now)},
+		{time.Second, Duration("k", time.Second)},
+		{err, Field{key: "k", fieldType: errorType, obj: err}},
+		{net.ParseIP("1.2.3.4"), Stringer("k", net.ParseIP("1.2.3.4"))},
+		{fakeUser{"fred"}, Marshaler("k", fakeUser{"fred"})},
+		{[]int{1}, Object("k", []int{1})},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Any("k", tt.value), "Unexpected field for %T.", tt.value)
+	}
+	assertFieldJSON(t, `"k":"fail"`, Any("k", err))
 }
 
 func TestDurationField(t *testing.T) {
@@ -225,3 +270,29 @@ func TestUnknownField(t *testing.T) {
 		assert.Panics(t, func() { field.AddTo(enc) }, "Expected panic when using a field of unknown type.")
 	}
 }
+
+func TestFieldKeyAndValue(t *testing.T) {
+	marshaler := LogMarshalerFunc(func(KeyValue) error { return nil })
+	tests := []struct {
+		field    Field
+		key      string
+		expected interface{}
+	}{
+		{Bool("b", true), "b", true},
+		{Int("i", 42), "i", 42},
+		{Int64("i64", 42), "i64", int64(42)},
+		{Uint("u", 42), "u", uint(42)},
+		{Float64("f", 1.5), "f", 1.5},
+		{String("s", "foo"), "s", "foo"},
+		{Stringer("ip", net.ParseIP("1.2.3.4")), "ip", "1.2.3.4"},
+		{Error(errors.New("fail")), "error", "fail"},
+		{Object("o", []int{1}), "o", []int{1}},
+		{Skip(), "", nil},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.key, tt.field.Key(), "Unexpected key.")
+		assert.Equal(t, tt.expected, tt.field.Value(), "Unexpected value for field %q.", tt.key)
+	}
+	assert.NotNil(t, Marshaler("m", marshaler).Value(), "Expected a LogMarshaler value.")
+}
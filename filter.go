@@ -32,7 +32,9 @@ type LeveledLogger struct {
 // This would be useful when seperating log into distinct files by its level. Different
 // loggers can have same level, the message would be written to all subloggers by multiLogger.
 //
-// It is inspired by the implementation of Tee
+// It is inspired by the implementation of Tee, and shares its development-mode
+// semantics: DPanic panics if any of the loggers registered at DPanicLevel
+// panic, but only after all of them have received the message.
 func Filter(logs ...LeveledLogger) Logger {
 	if len(logs) == 0 {
 		return nil
@@ -68,12 +70,23 @@ func (fl filterLogger) Error(msg string, fields ...Field) {
 
 func (fl filterLogger) Panic(msg string, fields ...Field) {
 	fl.log(PanicLevel, msg, fields)
-	panic(msg)
+	panic(panicValueVia(fl[PanicLevel-DebugLevel], PanicLevel, msg, fields))
 }
 
 func (fl filterLogger) Fatal(msg string, fields ...Field) {
 	fl.log(FatalLevel, msg, fields)
-	_exit(1)
+	exitVia(fl[FatalLevel-DebugLevel])
+}
+
+// PanicValue builds the panic value using the loggers registered at
+// PanicLevel, as Panic does.
+func (fl filterLogger) PanicValue(lvl Level, msg string, fields []Field) interface{} {
+	return panicValueVia(fl[PanicLevel-DebugLevel], lvl, msg, fields)
+}
+
+// Shutdown shuts down the loggers registered at FatalLevel, as Fatal does.
+func (fl filterLogger) Shutdown() (func(int), int) {
+	return shutdownAll(fl[FatalLevel-DebugLevel])
 }
 
 func (fl filterLogger) log(lvl Level, msg string, fields []Field) {
@@ -83,8 +96,7 @@ func (fl filterLogger) log(lvl Level, msg string, fields []Field) {
 }
 
 func (fl filterLogger) DPanic(msg string, fields ...Field) {
-	fl.log(DPanicLevel, msg, fields)
-	// TODO: Implement development/DPanic?
+	fl[DPanicLevel-DebugLevel].DPanic(msg, fields...)
 }
 
 func (fl filterLogger) With(fields ...Field) Logger {
@@ -98,6 +110,32 @@ func (fl filterLogger) With(fields ...Field) Logger {
 	return clone
 }
 
+func (fl filterLogger) Named(name string) Logger {
+	var clone filterLogger
+	for i := range fl {
+		clone[i] = make(multiLogger, len(fl[i]))
+		for j := range fl[i] {
+			clone[i][j] = fl[i][j].Named(name)
+		}
+	}
+	return clone
+}
+
+// Sync syncs the loggers registered at every level, even if some of them fail,
+// and returns their errors combined. Loggers registered at more than one level
+// are synced more than once.
+func (fl filterLogger) Sync() error {
+	var errs multiError
+	for _, ml := range fl {
+		for _, log := range ml {
+			if err := log.Sync(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs.asError()
+}
+
 func (fl filterLogger) Check(lvl Level, msg string) *CheckedMessage {
 	switch lvl {
 	case FatalLevel, PanicLevel:
@@ -21,10 +21,13 @@
 package zap_test
 
 import (
+	"errors"
+	"io/ioutil"
 	"testing"
 
 	"github.com/uber-go/zap"
 	"github.com/uber-go/zap/spy"
+	"github.com/uber-go/zap/spywrite"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -115,6 +118,74 @@ func TestFilter_Panic(t *testing.T) {
 	}, sink2.Logs())
 }
 
-// XXX: we cannot presently write `func TestTee_Fatal(t *testing.T)`,
-// because we can't have both a spy logger and an exit stub without a
-// dependency cycle.
+func TestFilter_Fatal(t *testing.T) {
+	var codes []int
+	log1, sink1 := spy.New(zap.DebugLevel, zap.ExitFunc(func(code int) { codes = append(codes, code) }))
+	log2, sink2 := spy.New(zap.DebugLevel)
+	log := zap.Filter(
+		zap.LeveledLogger{zap.FatalLevel, log1},
+		zap.LeveledLogger{zap.ErrorLevel, log2},
+	)
+
+	log.Fatal("foo")
+	assert.Equal(t, []spy.Log{{Level: zap.FatalLevel, Msg: "foo", Fields: []zap.Field{}}}, sink1.Logs(), "Unexpected output from the Fatal logger.")
+	assert.Empty(t, sink2.Logs(), "Unexpected output from the Error logger.")
+	assert.Equal(t, []int{1}, codes, "Expected to exit using the Fatal logger's settings.")
+}
+
+func TestFilterNamed(t *testing.T) {
+	log1, sink1 := spy.New(zap.DebugLevel)
+	log2, sink2 := spy.New(zap.DebugLevel)
+	log := zap.Filter(zap.LeveledLogger{zap.InfoLevel, log1}, zap.LeveledLogger{zap.WarnLevel, log2})
+	named := log.Named("db")
+	named.Info("info")
+	named.Warn("warn")
+
+	assert.Equal(t, []spy.Log{{
+		Level:  zap.InfoLevel,
+		Name:   "db",
+		Msg:    "info",
+		Fields: []zap.Field{},
+	}}, sink1.Logs(), "Expected the name to reach the Info logger.")
+	assert.Equal(t, []spy.Log{{
+		Level:  zap.WarnLevel,
+		Name:   "db",
+		Msg:    "warn",
+		Fields: []zap.Field{},
+	}}, sink2.Logs(), "Expected the name to reach the Warn logger.")
+}
+
+func TestFilterDPanic(t *testing.T) {
+	dev, devSink := spy.New(zap.DebugLevel, zap.Development())
+	prod, prodSink := spy.New(zap.DebugLevel)
+
+	log := zap.Filter(zap.LeveledLogger{zap.ErrorLevel, dev}, zap.LeveledLogger{zap.DPanicLevel, prod})
+	assert.NotPanics(t, func() { log.DPanic("prod only") }, "Expected no panic when development loggers don't receive the message.")
+
+	log = zap.Filter(zap.LeveledLogger{zap.DPanicLevel, dev}, zap.LeveledLogger{zap.DPanicLevel, prod})
+	assert.Panics(t, func() { log.DPanic("mixed") }, "Expected a panic when a development logger receives the message.")
+	assert.Panics(t, func() { log.Check(zap.DPanicLevel, "checked").Write() }, "Expected Check(DPanicLevel) to panic too.")
+
+	dpanic := func(msg string) spy.Log {
+		return spy.Log{Level: zap.DPanicLevel, Msg: msg, Fields: []zap.Field{}}
+	}
+	assert.Equal(t, []spy.Log{dpanic("mixed"), dpanic("checked")}, devSink.Logs(), "Unexpected output from development logger.")
+	assert.Equal(t, []spy.Log{dpanic("prod only"), dpanic("mixed"), dpanic("checked")}, prodSink.Logs(), "Unexpected output from production logger.")
+}
+
+func TestFilterSync(t *testing.T) {
+	log1, sink1 := spy.New()
+	log2, sink2 := spy.New()
+	failing := &spywrite.WriteSyncer{Writer: ioutil.Discard}
+	failing.SetError(errors.New("failed"))
+
+	log := zap.Filter(
+		zap.LeveledLogger{zap.InfoLevel, log1},
+		zap.LeveledLogger{zap.ErrorLevel, zap.New(zap.NewJSONEncoder(), zap.Output(failing))},
+		zap.LeveledLogger{zap.FatalLevel, log2},
+	)
+	assert.Error(t, log.Sync(), "Expected an error from the failing logger.")
+	assert.True(t, failing.Called(), "Expected to sync the failing logger.")
+	assert.Equal(t, 1, sink1.Syncs(), "Expected to sync the Info logger.")
+	assert.Equal(t, 1, sink2.Syncs(), "Expected to sync the Fatal logger.")
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// globals holds the process-global loggers, so that they can be swapped
+// together.
+type globals struct {
+	logger Logger
+	sugar  *SugaredLogger
+}
+
+var (
+	_globalMu sync.Mutex // serializes writers of _globals and the std log
+	_globals  atomic.Value
+)
+
+func init() {
+	nop := New(NullEncoder(), LevelEnablerFunc(func(Level) bool { return false }))
+	_globals.Store(globals{logger: nop, sugar: Sugar(nop)})
+}
+
+// L returns the process-global Logger, which libraries can use without having
+// it injected. Initially, it discards everything; applications should install
+// their own logger with ReplaceGlobals.
+//
+// It's safe for concurrent use, but the returned Logger isn't updated by later
+// calls to ReplaceGlobals, so avoid caching it for long.
+func L() Logger {
+	return _globals.Load().(globals).logger
+}
+
+// S returns a SugaredLogger wrapping the process-global Logger. See L.
+func S() *SugaredLogger {
+	return _globals.Load().(globals).sugar
+}
+
+// ReplaceGlobals replaces the process-global Logger returned by L and S. It
+// returns a function that restores the previous globals, which is
+// particularly useful in tests:
+//
+//   defer zap.ReplaceGlobals(logger)()
+func ReplaceGlobals(log Logger) func() {
+	_globalMu.Lock()
+	prev := _globals.Load().(globals)
+	_globals.Store(globals{logger: log, sugar: Sugar(log)})
+	_globalMu.Unlock()
+	return func() {
+		_globalMu.Lock()
+		_globals.Store(prev)
+		_globalMu.Unlock()
+	}
+}
+
+// RedirectStdLog sends the output of the standard library's package-global
+// log.Logger to the supplied Logger at InfoLevel. See RedirectStdLogAt.
+func RedirectStdLog(log Logger) func() {
+	undo, err := RedirectStdLogAt(log, InfoLevel)
+	if err != nil {
+		// Can't happen, since InfoLevel is valid.
+		panic(err)
+	}
+	return undo
+}
+
+// RedirectStdLogAt sends the output of the standard library's package-global
+// log.Logger to the supplied Logger at the given level. Each call to the log
+// package becomes one entry; the log package's prefix and flags are cleared,
+// since zap records the time itself, and trailing newlines are trimmed.
+//
+// Logging at PanicLevel or FatalLevel panics or exits just as the Logger's
+// Panic and Fatal methods do. It returns an error if the level is invalid, and
+// otherwise a function that restores the standard library's previous prefix
+// and flags. Since the log package can't report its current output, the
+// restore function always sends output back to os.Stderr, the log package's
+// default.
+func RedirectStdLogAt(l Logger, lvl Level) (func(), error) {
+	if lvl < DebugLevel || lvl > FatalLevel {
+		return nil, fmt.Errorf("can't redirect standard library log at invalid level %v", lvl)
+	}
+	_globalMu.Lock()
+	flags, prefix := log.Flags(), log.Prefix()
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(&loggerWriter{logger: l, lvl: lvl})
+	_globalMu.Unlock()
+	return func() {
+		_globalMu.Lock()
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(os.Stderr)
+		_globalMu.Unlock()
+	}, nil
+}
+
+// loggerWriter is the io.Writer behind RedirectStdLogAt. The log package calls
+// Write exactly once for each message, so each write becomes a single entry.
+type loggerWriter struct {
+	logger Logger
+	lvl    Level
+}
+
+func (w *loggerWriter) Write(b []byte) (int, error) {
+	msg := strings.TrimRight(string(b), "\r\n")
+	if cm := w.logger.Check(w.lvl, msg); cm.OK() {
+		cm.Write()
+	}
+	return len(b), nil
+}
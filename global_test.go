@@ -0,0 +1,114 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap_test
+
+import (
+	"log"
+	"sync"
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceGlobals(t *testing.T) {
+	initialL, initialS := zap.L(), zap.S()
+	assert.Nil(t, initialL.Check(zap.ErrorLevel, "nop"), "Expected the initial global logger to discard everything.")
+
+	logger, sink := spy.New()
+	undo := zap.ReplaceGlobals(logger)
+	zap.L().Info("no sugar")
+	zap.S().Infow("sugar", "n", 1)
+	assert.Equal(t, logger, zap.S().Desugar(), "Expected S to wrap the new global logger.")
+	undo()
+
+	zap.L().Info("discarded")
+	assert.Equal(t, []spy.Log{
+		{Level: zap.InfoLevel, Msg: "no sugar", Fields: []zap.Field{}},
+		{Level: zap.InfoLevel, Msg: "sugar", Fields: []zap.Field{zap.Int("n", 1)}},
+	}, sink.Logs(), "Unexpected global logger output.")
+	assert.Equal(t, initialL, zap.L(), "Expected undo to restore the original logger.")
+	assert.Equal(t, initialS, zap.S(), "Expected undo to restore the original sugared logger.")
+}
+
+func TestReplaceGlobalsRaces(t *testing.T) {
+	logger, _ := spy.New()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			zap.ReplaceGlobals(logger)()
+		}()
+		go func() {
+			defer wg.Done()
+			zap.L().Info("racing")
+			zap.S().Info("racing")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	flags, prefix := log.Flags(), log.Prefix()
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	log.SetPrefix("prefix: ")
+	defer func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}()
+
+	logger, sink := spy.New()
+	undo := zap.RedirectStdLog(logger)
+	log.Print("redirected")
+	log.Println("with newline")
+	undo()
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.InfoLevel, Msg: "redirected", Fields: []zap.Field{}},
+		{Level: zap.InfoLevel, Msg: "with newline", Fields: []zap.Field{}},
+	}, sink.Logs(), "Unexpected output from redirected standard library log.")
+	assert.Equal(t, log.LstdFlags|log.Lshortfile, log.Flags(), "Expected undo to restore flags.")
+	assert.Equal(t, "prefix: ", log.Prefix(), "Expected undo to restore the prefix.")
+}
+
+func TestRedirectStdLogAt(t *testing.T) {
+	logger, sink := spy.New(zap.WarnLevel)
+	undo, err := zap.RedirectStdLogAt(logger, zap.ErrorLevel)
+	require.NoError(t, err, "Unexpected error redirecting standard library log.")
+	log.Print("at error")
+	undo()
+
+	_, err = zap.RedirectStdLogAt(logger, zap.Level(42))
+	assert.Error(t, err, "Expected an error redirecting at an invalid level.")
+
+	undo, err = zap.RedirectStdLogAt(logger, zap.DebugLevel)
+	require.NoError(t, err, "Unexpected error redirecting standard library log.")
+	log.Print("disabled")
+	undo()
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.ErrorLevel, Msg: "at error", Fields: []zap.Field{}},
+	}, sink.Logs(), "Unexpected output from redirected standard library log.")
+}
@@ -23,8 +23,9 @@ package zap
 import (
 	"errors"
 	"path/filepath"
-	"runtime"
 	"strconv"
+
+	"github.com/uber-go/zap/internal/frames"
 )
 
 var (
@@ -35,10 +36,17 @@ var (
 	_callerSkip = 4
 )
 
-// A Hook is executed each time the logger writes an Entry. It can modify the
-// entry (including adding context to Entry.Fields()), but must not retain
-// references to the entry or any of its contents. Returned errors are written to
-// the logger's error output.
+// _maxCallerDepth bounds the number of frames searched for a caller.
+const _maxCallerDepth = 32
+
+// AddCallerField stores the caller under this key.
+const _callerKey = "caller"
+
+// A Hook is executed each time the logger writes an Entry, before the entry's
+// fields are encoded. It can modify the entry (including reading, editing,
+// adding, and removing Entry.Fields) or discard it entirely with Entry.Drop,
+// but must not retain references to the entry or any of its contents. Returned
+// errors are reported as internal errors with the cause "hook".
 //
 // Hooks implement the Option interface.
 type Hook func(*Entry) error
@@ -55,7 +63,7 @@ func AddCaller() Option {
 		if e == nil {
 			return errHookNilEntry
 		}
-		_, filename, line, ok := runtime.Caller(_callerSkip)
+		filename, line, ok := caller(_callerSkip)
 		if !ok {
 			return errCaller
 		}
@@ -77,6 +85,34 @@ func AddCaller() Option {
 	})
 }
 
+// AddCallerField configures the Logger to annotate each message with the
+// filename and line number of zap's caller under the "caller" key, rather than
+// prepending them to the message. It pairs well with the {caller} placeholder
+// in NewTemplateEncoder.
+func AddCallerField() Option {
+	return Hook(func(e *Entry) error {
+		if e == nil {
+			return errHookNilEntry
+		}
+		filename, line, ok := caller(_callerSkip)
+		if !ok {
+			return errCaller
+		}
+
+		// Re-use a buffer from the pool.
+		enc := jsonPool.Get().(*jsonEncoder)
+		enc.truncate()
+		buf := enc.bytes
+		buf = append(buf, filepath.Base(filename)...)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(line), 10)
+
+		e.Fields = append(e.Fields, String(_callerKey, string(buf)))
+		enc.Free()
+		return nil
+	})
+}
+
 // AddStacks configures the Logger to record a stack trace for all messages at
 // or above a given level. Keep in mind that this is (relatively speaking) quite
 // expensive.
@@ -86,8 +122,27 @@ func AddStacks(lvl Level) Option {
 			return errHookNilEntry
 		}
 		if e.Level >= lvl {
-			Stack().AddTo(e.Fields())
+			e.Fields = append(e.Fields, Stack())
 		}
 		return nil
 	})
 }
+
+// caller reports the location of the first stack frame, starting skip frames
+// above the calling hook, that doesn't belong to zap (test files excepted) or
+// to the standard library's log package. That attributes entries logged
+// through wrappers, CheckedMessages, and *log.Logger adapters to the code that
+// actually called them.
+func caller(skip int) (file string, line int, ok bool) {
+	// Skip caller itself.
+	for _, f := range frames.Callers(skip+1, _maxCallerDepth) {
+		if !isLoggingFrame(f) {
+			return f.File, f.Line, true
+		}
+	}
+	return "", 0, false
+}
+
+func isLoggingFrame(f frames.Frame) bool {
+	return frames.Package(f.Function) == "log" || frames.InZap(f)
+}
@@ -21,6 +21,7 @@
 package zap
 
 import (
+	"log"
 	"regexp"
 	"testing"
 
@@ -47,7 +48,7 @@ func TestHookAddCallerFail(t *testing.T) {
 
 	logger := New(NewJSONEncoder(), DebugLevel, Output(buf), ErrorOutput(errBuf), AddCaller())
 	logger.Info("Failure.")
-	assert.Regexp(t, `hook error: failed to get caller`, errBuf.String(), "Didn't find expected failure message.")
+	assert.Regexp(t, `"cause":"hook","error":"failed to get caller"`, errBuf.String(), "Didn't find expected failure message.")
 	assert.Contains(t, buf.String(), `"msg":"Failure."`, "Expected original message to survive failures in runtime.Caller.")
 }
 
@@ -76,6 +77,7 @@ func TestHooksNilEntry(t *testing.T) {
 	}{
 		{"AddStacks", AddStacks(InfoLevel).(Hook)},
 		{"AddCaller", AddCaller().(Hook)},
+		{"AddCallerField", AddCallerField().(Hook)},
 	}
 	for _, tt := range tests {
 		assert.NotPanics(t, func() {
@@ -83,3 +85,74 @@ func TestHooksNilEntry(t *testing.T) {
 		}, "Unexpected panic running hook %s on a nil message.", tt.name)
 	}
 }
+
+func TestHookEditsFields(t *testing.T) {
+	buf := &testBuffer{}
+	redact := Hook(func(e *Entry) error {
+		kept := e.Fields[:0]
+		for _, f := range e.Fields {
+			switch f.key {
+			case "password":
+				continue
+			case "user":
+				f = String("user", "REDACTED")
+			}
+			kept = append(kept, f)
+		}
+		e.Fields = append(kept, Int("hooked", 1))
+		return nil
+	})
+	logger := New(NewJSONEncoder(NoTime()), DebugLevel, Output(buf), redact)
+
+	fields := []Field{String("user", "jane"), String("password", "hunter2"), Int("n", 42)}
+	logger.Info("Edited.", fields...)
+	assert.Equal(
+		t,
+		`{"level":"info","msg":"Edited.","user":"REDACTED","n":42,"hooked":1}`,
+		buf.Stripped(),
+		"Unexpected output after editing fields in a hook.",
+	)
+	assert.Equal(t, String("password", "hunter2"), fields[1], "Hooks shouldn't modify the caller's fields.")
+}
+
+func TestHookDropsEntries(t *testing.T) {
+	buf := &testBuffer{}
+	var calls int
+	drop := Hook(func(e *Entry) error {
+		if e.Message == "drop me" {
+			e.Drop()
+		}
+		return nil
+	})
+	count := Hook(func(*Entry) error {
+		calls++
+		return nil
+	})
+	logger := New(NewJSONEncoder(NoTime()), DebugLevel, Output(buf), drop, count)
+
+	logger.Info("drop me", Int("n", 1))
+	assert.Equal(t, "", buf.String(), "Expected dropped entry to be discarded.")
+	assert.Equal(t, 0, calls, "Expected hooks after Drop to be skipped.")
+
+	logger.Info("keep me")
+	assert.Equal(t, `{"level":"info","msg":"keep me"}`, buf.Stripped(), "Expected entry to be written.")
+	assert.Equal(t, 1, calls, "Expected all hooks to run for kept entries.")
+}
+
+func TestHookAddCallerSkipsLoggingFrames(t *testing.T) {
+	buf := &testBuffer{}
+	logger := New(NewJSONEncoder(), DebugLevel, Output(buf), AddCallerField())
+	re := regexp.MustCompile(`"caller":"hook_test.go:\d+"`)
+
+	logger.Check(InfoLevel, "Checked.").Write()
+	assert.Regexp(t, re, buf.Stripped(), "Expected CheckedMessage.Write to be skipped.")
+
+	buf.Reset()
+	Sugar(logger).Infof("Sugared %d.", 1)
+	assert.Regexp(t, re, buf.Stripped(), "Expected SugaredLogger frames to be skipped.")
+
+	buf.Reset()
+	defer RedirectStdLog(logger)()
+	log.Print("Redirected.")
+	assert.Regexp(t, re, buf.Stripped(), "Expected standard library log frames to be skipped.")
+}
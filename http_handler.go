@@ -74,3 +74,91 @@ func (lvl AtomicLevel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 }
+
+// ServeHTTP supports inspecting and changing the registry's levels with HTTP
+// requests.
+//
+// GET requests return a JSON description of the default level, the rules, and
+// the effective level of every known logger:
+//   {"default":"info","rules":{"db.*":"debug"},"loggers":{"":"info","db.pool":"debug"}}
+// PUT requests set the level for a logger name or pattern, or the default
+// level if the name is omitted, and expect a payload like:
+//   {"name":"db.*","level":"debug"}
+// DELETE requests clear the rule for a name or pattern and expect a payload
+// like:
+//   {"name":"db.*"}
+// Successful PUT and DELETE requests return the updated description.
+func (r *LevelRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	type errorResponse struct {
+		Error string `json:"error"`
+	}
+	type payload struct {
+		Name  *string `json:"name"`
+		Level *Level  `json:"level"`
+	}
+	type state struct {
+		Default *Level            `json:"default"`
+		Rules   map[string]string `json:"rules"`
+		Loggers map[string]string `json:"loggers"`
+	}
+
+	enc := json.NewEncoder(w)
+	fail := func(code int, msg string) {
+		w.WriteHeader(code)
+		enc.Encode(errorResponse{Error: msg})
+	}
+	describe := func() {
+		dflt := r.DefaultLevel()
+		s := state{Default: &dflt, Rules: levelStrings(r.Rules()), Loggers: levelStrings(r.Loggers())}
+		enc.Encode(s)
+	}
+
+	switch req.Method {
+
+	case "GET":
+		describe()
+
+	case "PUT", "DELETE":
+		var p payload
+		if err := json.NewDecoder(req.Body).Decode(&p); err != nil {
+			fail(http.StatusBadRequest, fmt.Sprintf("Request body must be well-formed JSON: %v", err))
+			return
+		}
+
+		if req.Method == "DELETE" {
+			if p.Name == nil {
+				fail(http.StatusBadRequest, "Must specify a logger name or pattern.")
+				return
+			}
+			if !r.ClearLevel(*p.Name) {
+				fail(http.StatusNotFound, fmt.Sprintf("No level is set for %q.", *p.Name))
+				return
+			}
+			describe()
+			return
+		}
+
+		if p.Level == nil {
+			fail(http.StatusBadRequest, "Must specify a logging level.")
+			return
+		}
+		if p.Name == nil {
+			r.SetDefaultLevel(*p.Level)
+		} else if err := r.SetLevel(*p.Name, *p.Level); err != nil {
+			fail(http.StatusBadRequest, err.Error())
+			return
+		}
+		describe()
+
+	default:
+		fail(http.StatusMethodNotAllowed, "Only GET, PUT, and DELETE are supported.")
+	}
+}
+
+func levelStrings(levels map[string]Level) map[string]string {
+	strs := make(map[string]string, len(levels))
+	for name, lvl := range levels {
+		strs[name] = lvl.String()
+	}
+	return strs
+}
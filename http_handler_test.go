@@ -129,3 +129,48 @@ func TestHTTPHandlerMethodNotAllowed(t *testing.T) {
 	assertCodeMethodNotAllowed(t, code)
 	assertJSONError(t, body)
 }
+
+func TestLevelRegistryHTTPHandler(t *testing.T) {
+	reg := NewLevelRegistry(InfoLevel)
+	root, _ := spy.New(reg)
+	root.Named("db").Named("pool")
+
+	code, body := makeRequest(t, "GET", reg, nil)
+	assertCodeOK(t, code)
+	assert.JSONEq(t, `{"default":"info","rules":{},"loggers":{"":"info","db":"info","db.pool":"info"}}`, body, "Unexpected GET response.")
+
+	code, body = makeRequest(t, "PUT", reg, strings.NewReader(`{"name":"db.*","level":"debug"}`))
+	assertCodeOK(t, code)
+	assert.JSONEq(t, `{"default":"info","rules":{"db.*":"debug"},"loggers":{"":"info","db":"info","db.pool":"debug"}}`, body, "Unexpected PUT response.")
+
+	code, body = makeRequest(t, "PUT", reg, strings.NewReader(`{"level":"warn"}`))
+	assertCodeOK(t, code)
+	assert.JSONEq(t, `{"default":"warn","rules":{"db.*":"debug"},"loggers":{"":"warn","db":"warn","db.pool":"debug"}}`, body, "Unexpected response setting the default.")
+
+	code, body = makeRequest(t, "DELETE", reg, strings.NewReader(`{"name":"db.*"}`))
+	assertCodeOK(t, code)
+	assert.JSONEq(t, `{"default":"warn","rules":{},"loggers":{"":"warn","db":"warn","db.pool":"warn"}}`, body, "Unexpected DELETE response.")
+}
+
+func TestLevelRegistryHTTPHandlerErrors(t *testing.T) {
+	reg := NewLevelRegistry(InfoLevel)
+	tests := []struct {
+		method string
+		body   string
+		code   int
+	}{
+		{"PUT", `{`, http.StatusBadRequest},
+		{"PUT", `{"name":"db"}`, http.StatusBadRequest},
+		{"PUT", `{"name":"db","level":"unrecognized-level"}`, http.StatusBadRequest},
+		{"PUT", `{"name":"db.[","level":"info"}`, http.StatusBadRequest},
+		{"DELETE", `{}`, http.StatusBadRequest},
+		{"DELETE", `{"name":"missing"}`, http.StatusNotFound},
+		{"POST", `{}`, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		code, body := makeRequest(t, tt.method, reg, strings.NewReader(tt.body))
+		assert.Equal(t, tt.code, code, "Unexpected status for %s %s.", tt.method, tt.body)
+		assertJSONError(t, body)
+	}
+	assert.Empty(t, reg.Rules(), "Expected failed requests not to change the rules.")
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// +build !go1.7
+
+package frames
+
+import "runtime"
+
+// Callers returns up to depth frames from the calling goroutine's stack,
+// starting skip frames above the caller of Callers. Before Go 1.7, the
+// runtime can't expand inlined calls, so they're attributed to the function
+// they were inlined into.
+func Callers(skip, depth int) []Frame {
+	pcs := make([]uintptr, depth)
+	// Skip runtime.Callers and Callers itself.
+	n := runtime.Callers(skip+2, pcs)
+	frames := make([]Frame, 0, n)
+	for _, pc := range pcs[:n] {
+		// Each pc is a return address; step back into the call instruction so
+		// that the reported line is that of the call.
+		fn := runtime.FuncForPC(pc - 1)
+		if fn == nil {
+			continue
+		}
+		file, line := fn.FileLine(pc - 1)
+		frames = append(frames, Frame{Function: fn.Name(), File: file, Line: line})
+	}
+	return frames
+}
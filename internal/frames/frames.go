@@ -0,0 +1,61 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package frames inspects the call stack on behalf of zap and its wrappers,
+// so that they can attribute entries to the code that logged them. It's
+// internal to zap.
+package frames
+
+import "strings"
+
+// ZapPackage prefixes the import paths of zap and its wrappers.
+const ZapPackage = "github.com/uber-go/zap"
+
+// A Frame describes a single function call on the stack.
+type Frame struct {
+	// Function is the fully-qualified function name, like
+	// "github.com/uber-go/zap.(*logger).Info".
+	Function string
+	File     string
+	Line     int
+}
+
+// Package extracts the import path from a fully-qualified function name like
+// "github.com/uber-go/zap.(*logger).Info".
+func Package(fn string) string {
+	slash := strings.LastIndex(fn, "/")
+	if dot := strings.Index(fn[slash+1:], "."); dot >= 0 {
+		return fn[:slash+1+dot]
+	}
+	return fn
+}
+
+// InZap reports whether a frame belongs to zap or one of its wrappers. Frames
+// from test files don't count, so that zap's own tests look like callers.
+func InZap(f Frame) bool {
+	pkg := Package(f.Function)
+	inZap := pkg == ZapPackage || strings.HasPrefix(pkg, ZapPackage+"/")
+	return inZap && !strings.HasSuffix(f.File, "_test.go")
+}
+
+// InRuntime reports whether a frame belongs to the Go runtime.
+func InRuntime(f Frame) bool {
+	return strings.HasPrefix(f.Function, "runtime.")
+}
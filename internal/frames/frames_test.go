@@ -0,0 +1,76 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package frames
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackage(t *testing.T) {
+	tests := []struct {
+		fn       string
+		expected string
+	}{
+		{"github.com/uber-go/zap.(*logger).Info", "github.com/uber-go/zap"},
+		{"github.com/uber-go/zap/zwrap.(*filter).Info", "github.com/uber-go/zap/zwrap"},
+		{"log.Printf", "log"},
+		{"main.main.func1", "main"},
+		{"nodots", "nodots"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Package(tt.fn), "Unexpected package for %q.", tt.fn)
+	}
+}
+
+func TestInZap(t *testing.T) {
+	tests := []struct {
+		frame    Frame
+		expected bool
+	}{
+		{Frame{Function: "github.com/uber-go/zap.(*logger).Info", File: "logger.go"}, true},
+		{Frame{Function: "github.com/uber-go/zap/zwrap.(*filter).Info", File: "filter.go"}, true},
+		{Frame{Function: "github.com/uber-go/zap.TestFoo", File: "logger_test.go"}, false},
+		{Frame{Function: "github.com/uber-go/zapper.Foo", File: "zapper.go"}, false},
+		{Frame{Function: "main.main", File: "main.go"}, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, InZap(tt.frame), "Unexpected result for frame %+v.", tt.frame)
+	}
+}
+
+func helper() []Frame {
+	return Callers(0, 8)
+}
+
+func TestCallers(t *testing.T) {
+	frames := helper()
+	if assert.True(t, len(frames) >= 2, "Expected at least two frames.") {
+		assert.Equal(t, "github.com/uber-go/zap/internal/frames.helper", frames[0].Function, "Expected the first frame to be the caller of Callers.")
+		assert.Equal(t, "github.com/uber-go/zap/internal/frames.TestCallers", frames[1].Function, "Unexpected second frame.")
+		assert.Contains(t, frames[1].File, "frames_test.go", "Unexpected file for the second frame.")
+		assert.True(t, frames[1].Line > 0, "Expected a line number.")
+	}
+	assert.Equal(t, 1, len(Callers(0, 1)), "Expected Callers to respect the depth.")
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package names matches logger names against the patterns used to select
+// loggers by name, as in zap.Route and zwrap.Rule. It's internal to zap.
+package names
+
+import (
+	"path"
+	"strings"
+)
+
+// Match reports whether a logger name matches a pattern. A name matches
+// itself and its descendants (e.g., "db" matches "db.pool"), and patterns with
+// glob metacharacters are matched using path.Match.
+func Match(pattern, name string) bool {
+	if pattern == name || strings.HasPrefix(name, pattern+".") {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+// Validate returns an error if the pattern is malformed.
+func Validate(pattern string) error {
+	_, err := path.Match(pattern, "")
+	return err
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package names
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"db", "db", true},
+		{"db", "db.pool", true},
+		{"db", "dbx", false},
+		{"db.pool", "db", false},
+		{"*", "", true},
+		{"*", "db", true},
+		{"db.*", "db.pool", true},
+		{"db.*", "db.pool.conn", true},
+		{"db.p*", "db.conn", false},
+		{"[", "db", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Match(tt.pattern, tt.name), "Unexpected result matching %q against %q.", tt.name, tt.pattern)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate("db.*"), "Unexpected error validating a glob.")
+	assert.Error(t, Validate("["), "Expected an error validating a malformed glob.")
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uber-go/atomic"
+)
+
+// _defaultErrorInterval is the minimum time between two reports of internal
+// errors with the same cause.
+const _defaultErrorInterval = time.Second
+
+// The causes of internal errors.
+const (
+	encoderCause    = "encoder"
+	hookCause       = "hook"
+	writeCause      = "write"
+	shortWriteCause = "short write"
+	shutdownCause   = "shutdown"
+	syncCause       = "sync"
+)
+
+// An ErrorReport describes an internal logger problem, such as an entry that
+// couldn't be written.
+type ErrorReport struct {
+	Time time.Time
+	// Cause is one of "encoder", "hook", "write", "short write", "shutdown",
+	// or "sync", or whatever other cause was passed to Meta.InternalError.
+	Cause string
+	Err   error
+	// Suppressed counts the errors with the same cause that weren't reported
+	// since the previous report, because of rate limiting.
+	Suppressed uint64
+}
+
+// MarshalLog implements LogMarshaler, so handlers can log reports elsewhere.
+func (r ErrorReport) MarshalLog(kv KeyValue) error {
+	kv.AddString("time", r.Time.Format(time.RFC3339Nano))
+	kv.AddString("cause", r.Cause)
+	kv.AddString("error", r.Err.Error())
+	kv.AddUint64("suppressed", r.Suppressed)
+	return nil
+}
+
+// An ErrorHandler receives reports of internal errors in place of the logger's
+// ErrorOutput. Reports are rate-limited before they reach the handler, and
+// the handler may be called concurrently.
+type ErrorHandler func(ErrorReport)
+
+// ErrorCounts is a snapshot of the internal errors seen by an ErrorTracker,
+// including those that weren't reported because of rate limiting.
+type ErrorCounts struct {
+	Encoder    uint64
+	Hook       uint64
+	Write      uint64
+	ShortWrite uint64
+	Shutdown   uint64
+	// Other counts errors with any other cause, including failures to sync
+	// after recovering a panic (see RecoverAndLog).
+	Other uint64
+}
+
+// An ErrorTracker counts a logger's internal errors and limits how often
+// they're reported: for each cause, at most one report is made per interval,
+// and the next report includes the number of errors suppressed in the
+// meantime. Rate limiting always uses the system clock, even if the logger's
+// Clock is stopped or faked.
+//
+// ErrorTrackers implement the Option interface. By default, each logger (and
+// its children) shares a tracker that reports at most once per second and
+// cause; pass the same tracker to several loggers to combine their counts.
+type ErrorTracker struct {
+	interval time.Duration
+	now      func() time.Time
+
+	encoder    atomic.Uint64
+	hook       atomic.Uint64
+	write      atomic.Uint64
+	shortWrite atomic.Uint64
+	shutdown   atomic.Uint64
+	other      atomic.Uint64
+
+	mu      sync.Mutex
+	limiter map[string]*causeLimiter
+}
+
+type causeLimiter struct {
+	last       time.Time
+	suppressed uint64
+}
+
+// NewErrorTracker creates an ErrorTracker that reports each cause of internal
+// errors at most once per interval. Non-positive intervals disable rate
+// limiting.
+func NewErrorTracker(interval time.Duration) *ErrorTracker {
+	return &ErrorTracker{
+		interval: interval,
+		now:      time.Now,
+		limiter:  make(map[string]*causeLimiter),
+	}
+}
+
+func (et *ErrorTracker) apply(m *Meta) {
+	m.ErrorTracker = et
+}
+
+// Counts returns the number of internal errors seen so far, by cause.
+func (et *ErrorTracker) Counts() ErrorCounts {
+	return ErrorCounts{
+		Encoder:    et.encoder.Load(),
+		Hook:       et.hook.Load(),
+		Write:      et.write.Load(),
+		ShortWrite: et.shortWrite.Load(),
+		Shutdown:   et.shutdown.Load(),
+		Other:      et.other.Load(),
+	}
+}
+
+// track counts an error and decides whether to report it. If so, it returns
+// the number of errors suppressed since the last report.
+func (et *ErrorTracker) track(cause string) (suppressed uint64, report bool) {
+	switch cause {
+	case encoderCause:
+		et.encoder.Inc()
+	case hookCause:
+		et.hook.Inc()
+	case writeCause:
+		et.write.Inc()
+	case shortWriteCause:
+		et.shortWrite.Inc()
+	case shutdownCause:
+		et.shutdown.Inc()
+	default:
+		et.other.Inc()
+	}
+
+	now := et.now()
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	l, ok := et.limiter[cause]
+	if !ok {
+		et.limiter[cause] = &causeLimiter{last: now}
+		return 0, true
+	}
+	if et.interval > 0 && now.Sub(l.last) < et.interval {
+		l.suppressed++
+		return 0, false
+	}
+	suppressed, l.suppressed, l.last = l.suppressed, 0, now
+	return suppressed, true
+}
+
+// errorCause refines the cause of an internal error using its type, so that
+// failures to write are distinguished from failures to encode.
+func errorCause(cause string, err error) string {
+	switch err.(type) {
+	case writeError:
+		return writeCause
+	case shortWriteError:
+		return shortWriteCause
+	}
+	return cause
+}
+
+// writeErrorReport writes the report to the sink as a line of JSON, then syncs
+// the sink.
+func writeErrorReport(sink WriteSyncer, r ErrorReport) {
+	enc := jsonPool.Get().(*jsonEncoder)
+	enc.truncate()
+	enc.jsonConfig = jsonConfig{}
+	enc.bytes = append(enc.bytes, '{')
+	r.MarshalLog(enc)
+	enc.bytes = append(enc.bytes, '}', '\n')
+	sink.Write(enc.bytes)
+	enc.Free()
+	sink.Sync()
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber-go/zap/spywrite"
+	"github.com/uber-go/zap/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInternalErrorRateLimiting(t *testing.T) {
+	errBuf := &testBuffer{}
+	clock := testutils.NewFakeClock(time.Unix(0, 0))
+	tracker := NewErrorTracker(time.Second)
+	tracker.now = clock.Now
+	logger := New(
+		newJSONEncoder(),
+		Output(AddSync(spywrite.FailWriter{})),
+		ErrorOutput(errBuf),
+		WithClock(clock),
+		tracker,
+	)
+
+	for i := 0; i < 3; i++ {
+		logger.Info("dropped")
+	}
+	clock.Add(500 * time.Millisecond)
+	logger.With(String("foo", "bar")).Info("dropped")
+	clock.Add(500 * time.Millisecond)
+	logger.Info("dropped")
+
+	assert.Equal(t, []string{
+		`{"time":"1970-01-01T00:00:00Z","cause":"write","error":"failed","suppressed":0}`,
+		`{"time":"1970-01-01T00:00:01Z","cause":"write","error":"failed","suppressed":3}`,
+	}, errBuf.Lines(), "Expected repeated errors to be rate-limited.")
+}
+
+func TestInternalErrorCounts(t *testing.T) {
+	tracker := NewErrorTracker(0)
+	var reports []ErrorReport
+	handler := OnInternalError(func(r ErrorReport) { reports = append(reports, r) })
+	failHook := Hook(func(*Entry) error { return errors.New("hook failed") })
+
+	short := New(newJSONEncoder(), tracker, handler, Output(AddSync(spywrite.ShortWriter{})))
+	failing := New(newJSONEncoder(), tracker, handler, Output(AddSync(spywrite.FailWriter{})), failHook)
+	short.Info("short")
+	short.Info("short")
+	failing.Info("fail")
+	Meta{ErrorTracker: tracker, ErrorHandler: func(ErrorReport) {}}.InternalError("encoder", errors.New("bad"))
+	Meta{ErrorTracker: tracker, ErrorHandler: func(ErrorReport) {}}.InternalError("custom", errors.New("odd"))
+
+	assert.Equal(t, ErrorCounts{Encoder: 1, Hook: 1, Write: 1, ShortWrite: 2, Other: 1}, tracker.Counts(), "Unexpected error counts.")
+	if assert.Equal(t, 4, len(reports), "Expected every error to be reported without rate limiting.") {
+		assert.Equal(t, shortWriteCause, reports[0].Cause, "Unexpected cause for a short write.")
+		assert.Regexp(t, `^incomplete write: only wrote \d+ of \d+ bytes$`, reports[0].Err.Error(), "Unexpected short write error.")
+		assert.Equal(t, hookCause, reports[2].Cause, "Unexpected cause for a hook failure.")
+		assert.Equal(t, writeCause, reports[3].Cause, "Unexpected cause for a write failure.")
+	}
+}
+
+func TestInternalErrorRateLimitingIgnoresLoggerClock(t *testing.T) {
+	var reports []ErrorReport
+	logger := New(
+		newJSONEncoder(),
+		Output(AddSync(spywrite.FailWriter{})),
+		WithClock(testutils.NewFakeClock(time.Unix(0, 0))),
+		NewErrorTracker(time.Millisecond),
+		OnInternalError(func(r ErrorReport) { reports = append(reports, r) }),
+	)
+
+	logger.Info("reported")
+	time.Sleep(10 * time.Millisecond)
+	logger.Info("reported despite the stopped clock")
+	assert.Equal(t, 2, len(reports), "Expected rate limiting to use the system clock.")
+}
@@ -23,12 +23,12 @@ package zap
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"math"
 	"strconv"
 	"sync"
 	"time"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
@@ -47,6 +47,7 @@ var (
 	defaultMessageF = MessageKey("msg")
 	defaultTimeF    = EpochFormatter("ts")
 	defaultLevelF   = LevelString("level")
+	defaultNameF    = NameKey("logger")
 
 	jsonPool = sync.Pool{New: func() interface{} {
 		return &jsonEncoder{
@@ -58,17 +59,35 @@ var (
 
 // jsonEncoder is an Encoder implementation that writes JSON.
 type jsonEncoder struct {
-	bytes    []byte
-	messageF MessageFormatter
-	timeF    TimeFormatter
-	levelF   LevelFormatter
+	jsonConfig
+
+	bytes []byte
+	spans []fieldSpan
+	depth int
+	name  string
+}
+
+// jsonConfig holds the options set by JSONOptions, all of which are shared
+// with clones.
+type jsonConfig struct {
+	messageF   MessageFormatter
+	timeF      TimeFormatter
+	levelF     LevelFormatter
+	nameF      NameFormatter
+	htmlSafe   bool
+	asciiOnly  bool
+	sortKeys   bool
+	maxMessage int
+	maxString  int
+	maxDepth   int
+	maxEntry   int
 }
 
 // NewJSONEncoder creates a fast, low-allocation JSON encoder. By default, JSON
 // encoders put the log message under the "msg" key, the timestamp (as
-// floating-point seconds since epoch) under the "ts" key, and the log level
-// under the "level" key. The encoder appropriately escapes all field keys and
-// values.
+// floating-point seconds since epoch) under the "ts" key, the log level under
+// the "level" key, and the name of Named loggers under the "logger" key. The
+// encoder appropriately escapes all field keys and values.
 //
 // Note that the encoder doesn't deduplicate keys, so it's possible to produce a
 // message like
@@ -81,9 +100,12 @@ func NewJSONEncoder(options ...JSONOption) Encoder {
 	enc := jsonPool.Get().(*jsonEncoder)
 	enc.truncate()
 
-	enc.messageF = defaultMessageF
-	enc.timeF = defaultTimeF
-	enc.levelF = defaultLevelF
+	enc.jsonConfig = jsonConfig{
+		messageF: defaultMessageF,
+		timeF:    defaultTimeF,
+		levelF:   defaultLevelF,
+		nameF:    defaultNameF,
+	}
 	for _, opt := range options {
 		opt.apply(enc)
 	}
@@ -92,15 +114,21 @@ func NewJSONEncoder(options ...JSONOption) Encoder {
 }
 
 func (enc *jsonEncoder) Free() {
+	if cap(enc.bytes) > _maxPooledBufSize {
+		// Don't let one enormous entry pin a large buffer for the life of the
+		// process.
+		return
+	}
 	jsonPool.Put(enc)
 }
 
 // AddString adds a string key and value to the encoder's fields. Both key and
-// value are JSON-escaped.
+// value are JSON-escaped, and the value is truncated if it's longer than the
+// configured MaxStringLength.
 func (enc *jsonEncoder) AddString(key, val string) {
 	enc.addKey(key)
 	enc.bytes = append(enc.bytes, '"')
-	enc.safeAddString(val)
+	enc.safeAddLimitedString(val, enc.maxString)
 	enc.bytes = append(enc.bytes, '"')
 }
 
@@ -159,34 +187,77 @@ func (enc *jsonEncoder) AddFloat64(key string, val float64) {
 	}
 }
 
-// AddMarshaler adds a LogMarshaler to the encoder's fields.
+// AddMarshaler adds a LogMarshaler to the encoder's fields. If the object
+// would be nested more deeply than the configured MaxNestingDepth, it's
+// encoded as an empty object and AddMarshaler returns an error.
 func (enc *jsonEncoder) AddMarshaler(key string, obj LogMarshaler) error {
 	enc.addKey(key)
 	enc.bytes = append(enc.bytes, '{')
+	if enc.maxDepth > 0 && enc.depth >= enc.maxDepth {
+		enc.bytes = append(enc.bytes, '}')
+		return errMaxDepth
+	}
+	enc.depth++
+	mark := len(enc.spans)
 	err := obj.MarshalLog(enc)
+	enc.depth--
+	if enc.trackSpans() {
+		// The nested object is complete, so we can sort it in place and forget
+		// about its fields.
+		if enc.sortKeys {
+			sortTrailingFields(enc.bytes, enc.spans[mark:], ',')
+		}
+		enc.spans = enc.spans[:mark]
+	}
 	enc.bytes = append(enc.bytes, '}')
 	return err
 }
 
 // AddObject uses reflection to add an arbitrary object to the logging context.
+// If the object's JSON representation is longer than the configured
+// MaxStringLength, it's truncated and added as a string instead.
 func (enc *jsonEncoder) AddObject(key string, obj interface{}) error {
 	marshaled, err := json.Marshal(obj)
 	if err != nil {
 		return err
 	}
+	if enc.maxString > 0 && len(marshaled) > enc.maxString {
+		enc.AddString(key, string(marshaled))
+		return nil
+	}
 	enc.addKey(key)
-	enc.bytes = append(enc.bytes, marshaled...)
+	if enc.asciiOnly {
+		enc.addASCIIOnly(marshaled)
+	} else {
+		enc.bytes = append(enc.bytes, marshaled...)
+	}
 	return nil
 }
 
+// addASCIIOnly appends JSON produced by encoding/json, escaping any non-ASCII
+// characters. Since encoding/json only emits non-ASCII bytes inside strings
+// and replaces invalid UTF-8, they can all be escaped as runes.
+func (enc *jsonEncoder) addASCIIOnly(b []byte) {
+	for i := 0; i < len(b); {
+		if b[i] < utf8.RuneSelf {
+			enc.bytes = append(enc.bytes, b[i])
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(b[i:])
+		enc.addRuneEscape(r)
+		i += size
+	}
+}
+
 // Clone copies the current encoder, including any data already encoded.
 func (enc *jsonEncoder) Clone() Encoder {
 	clone := jsonPool.Get().(*jsonEncoder)
 	clone.truncate()
 	clone.bytes = append(clone.bytes, enc.bytes...)
-	clone.messageF = enc.messageF
-	clone.timeF = enc.timeF
-	clone.levelF = enc.levelF
+	clone.spans = append(clone.spans, enc.spans...)
+	clone.jsonConfig = enc.jsonConfig
+	clone.name = enc.name
 	return clone
 }
 
@@ -201,54 +272,148 @@ func (enc *jsonEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time
 
 	final := jsonPool.Get().(*jsonEncoder)
 	final.truncate()
-	final.bytes = append(final.bytes, '{')
+	final.jsonConfig = jsonConfig{htmlSafe: enc.htmlSafe, asciiOnly: enc.asciiOnly}
+	enc.addHeader(final, msg, enc.maxMessage, lvl, t)
+	if enc.maxEntry > 0 {
+		enc.shrinkHeader(final, msg, lvl, t)
+	}
+	enc.addContext(final)
+	final.bytes = append(final.bytes, '}', '\n')
+
+	err := writeEntry(sink, final.bytes)
+	final.Free()
+	return err
+}
+
+// addHeader resets the final encoder and adds the entry's level, time, and
+// message, truncating the message to msgLimit bytes (if positive).
+func (enc *jsonEncoder) addHeader(final *jsonEncoder, msg string, msgLimit int, lvl Level, t time.Time) {
+	final.bytes = append(final.bytes[:0], '{')
 	enc.levelF(lvl).AddTo(final)
 	enc.timeF(t).AddTo(final)
+	if enc.name != "" {
+		enc.nameF(enc.name).AddTo(final)
+	}
+	final.maxString = msgLimit
 	enc.messageF(msg).AddTo(final)
-	if len(enc.bytes) > 0 {
-		if len(final.bytes) > 1 {
-			// All the formatters may have been no-ops.
-			final.bytes = append(final.bytes, ',')
+	final.maxString = 0
+}
+
+// shrinkHeader re-encodes the entry's header with successively shorter
+// messages until the header fits within MaxEntrySize. Since escaping may
+// lengthen the message, this may take a few attempts.
+func (enc *jsonEncoder) shrinkHeader(final *jsonEncoder, msg string, lvl Level, t time.Time) {
+	limit := len(msg)
+	if enc.maxMessage > 0 && enc.maxMessage < limit {
+		limit = enc.maxMessage
+	}
+	for limit > 1 {
+		over := len(final.bytes) + len("}\n") - enc.maxEntry
+		if over <= 0 {
+			return
 		}
+		limit -= over
+		if limit < 1 {
+			limit = 1
+		}
+		enc.addHeader(final, msg, limit, lvl, t)
+	}
+}
+
+// addContext adds the encoder's accumulated fields to the final encoder,
+// sorting them and dropping those that don't fit within MaxEntrySize as
+// necessary. It doesn't modify the receiver.
+func (enc *jsonEncoder) addContext(final *jsonEncoder) {
+	if len(enc.bytes) == 0 {
+		return
+	}
+	fits := enc.maxEntry <= 0 || len(final.bytes)+len(",")+len(enc.bytes)+len("}\n") <= enc.maxEntry
+	if fits && !enc.sortKeys {
+		final.addSeparator()
 		final.bytes = append(final.bytes, enc.bytes...)
+		return
 	}
-	final.bytes = append(final.bytes, '}', '\n')
 
-	expectedBytes := len(final.bytes)
-	n, err := sink.Write(final.bytes)
-	final.Free()
-	if err != nil {
-		return err
+	// Work on a copy of the spans, since WriteEntry mustn't modify the
+	// receiver.
+	final.spans = append(final.spans[:0], enc.spans...)
+	measureSpans(final.spans, len(enc.bytes))
+	if enc.sortKeys {
+		sortSpans(enc.bytes, final.spans)
 	}
-	if n != expectedBytes {
-		return fmt.Errorf("incomplete write: only wrote %v of %v bytes", n, expectedBytes)
+	if fits {
+		final.addSeparator()
+		final.bytes = appendSpans(final.bytes, enc.bytes, final.spans, ',')
+		return
+	}
+
+	budget := enc.maxEntry - len("}\n") - _truncatedFieldReserve
+	dropped := 0
+	for _, s := range final.spans {
+		size := s.end - s.start
+		if len(final.bytes)+len(",")+size > budget {
+			dropped += size
+			continue
+		}
+		final.addSeparator()
+		final.bytes = append(final.bytes, enc.bytes[s.start:s.end]...)
+	}
+	if dropped > 0 {
+		final.addKey(_truncatedKey)
+		final.bytes = append(final.bytes, '"')
+		final.addTruncationMarker(dropped)
+		final.bytes = append(final.bytes, '"')
 	}
-	return nil
+}
+
+// trackSpans reports whether the encoder needs to know where each field
+// starts and ends.
+func (enc *jsonEncoder) trackSpans() bool {
+	return enc.sortKeys || enc.maxEntry > 0
 }
 
 func (enc *jsonEncoder) truncate() {
 	enc.bytes = enc.bytes[:0]
+	enc.spans = enc.spans[:0]
+	enc.depth = 0
+	enc.name = ""
 }
 
-func (enc *jsonEncoder) addKey(key string) {
-	last := len(enc.bytes) - 1
-	// At some point, we'll also want to support arrays.
-	if last >= 0 && enc.bytes[last] != '{' {
+func (enc *jsonEncoder) setName(name string) {
+	enc.name = name
+}
+
+func (enc *jsonEncoder) addSeparator() {
+	if last := len(enc.bytes) - 1; last >= 0 && enc.bytes[last] != '{' {
 		enc.bytes = append(enc.bytes, ',')
 	}
+}
+
+func (enc *jsonEncoder) addKey(key string) {
+	// At some point, we'll also want to support arrays.
+	enc.addSeparator()
+	start := len(enc.bytes)
 	enc.bytes = append(enc.bytes, '"')
 	enc.safeAddString(key)
 	enc.bytes = append(enc.bytes, '"', ':')
+	if enc.trackSpans() {
+		enc.spans = append(enc.spans, fieldSpan{
+			start:    start,
+			keyStart: start + 1,
+			keyEnd:   len(enc.bytes) - 2,
+		})
+	}
 }
 
 // safeAddString JSON-escapes a string and appends it to the internal buffer.
 // Unlike the standard library's escaping function, it doesn't attempt to
-// protect the user from browser vulnerabilities or JSONP-related problems.
+// protect the user from browser vulnerabilities or JSONP-related problems
+// unless the encoder was constructed with the HTMLSafe option.
 func (enc *jsonEncoder) safeAddString(s string) {
 	for i := 0; i < len(s); {
 		if b := s[i]; b < utf8.RuneSelf {
 			i++
-			if 0x20 <= b && b != '\\' && b != '"' {
+			if 0x20 <= b && b != '\\' && b != '"' && !(enc.htmlSafe && isHTMLSpecial(b)) {
 				enc.bytes = append(enc.bytes, b)
 				continue
 			}
@@ -274,7 +439,50 @@ func (enc *jsonEncoder) safeAddString(s string) {
 			i++
 			continue
 		}
-		enc.bytes = append(enc.bytes, s[i:i+size]...)
+		if enc.asciiOnly || (enc.htmlSafe && (c == '\u2028' || c == '\u2029')) {
+			enc.addRuneEscape(c)
+		} else {
+			enc.bytes = append(enc.bytes, s[i:i+size]...)
+		}
 		i += size
 	}
 }
+
+// safeAddLimitedString JSON-escapes at most limit bytes of a string, marking
+// any truncation. Non-positive limits disable truncation.
+func (enc *jsonEncoder) safeAddLimitedString(s string, limit int) {
+	if limit <= 0 || len(s) <= limit {
+		enc.safeAddString(s)
+		return
+	}
+	cut := truncationPoint(s, limit)
+	enc.safeAddString(s[:cut])
+	enc.addTruncationMarker(len(s) - cut)
+}
+
+// addTruncationMarker appends a marker recording that n bytes were dropped.
+func (enc *jsonEncoder) addTruncationMarker(n int) {
+	enc.safeAddString(_truncatedPrefix)
+	enc.bytes = strconv.AppendInt(enc.bytes, int64(n), 10)
+	enc.bytes = append(enc.bytes, _truncatedSuffix...)
+}
+
+// addRuneEscape appends the \uXXXX escape sequence for a rune, splitting runes
+// outside the Basic Multilingual Plane into a UTF-16 surrogate pair.
+func (enc *jsonEncoder) addRuneEscape(r rune) {
+	if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		enc.addUTF16Escape(r1)
+		enc.addUTF16Escape(r2)
+		return
+	}
+	enc.addUTF16Escape(r)
+}
+
+func (enc *jsonEncoder) addUTF16Escape(r rune) {
+	enc.bytes = append(enc.bytes, '\\', 'u')
+	enc.bytes = append(enc.bytes, _hex[r>>12&0xF], _hex[r>>8&0xF], _hex[r>>4&0xF], _hex[r&0xF])
+}
+
+func isHTMLSpecial(b byte) bool {
+	return b == '<' || b == '>' || b == '&'
+}
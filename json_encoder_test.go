@@ -22,6 +22,7 @@ package zap
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -274,6 +275,207 @@ func TestJSONEscaping(t *testing.T) {
 	}
 }
 
+func TestJSONEscapingModes(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []JSONOption
+		input    string
+		expected string
+	}{
+		{"default", nil, "<a & b>", "<a & b>"},
+		{"default", nil, "☃\u2028", "☃\u2028"},
+		{"HTMLSafe", []JSONOption{HTMLSafe()}, "<a & b>", `\u003ca \u0026 b\u003e`},
+		{"HTMLSafe", []JSONOption{HTMLSafe()}, "a\u2028b\u2029", `a\u2028b\u2029`},
+		{"HTMLSafe", []JSONOption{HTMLSafe()}, `"☃"`, `\"☃\"`},
+		{"ASCIIOnly", []JSONOption{ASCIIOnly()}, "<foo>", "<foo>"},
+		{"ASCIIOnly", []JSONOption{ASCIIOnly()}, "é☃", `\u00e9\u2603`},
+		{"ASCIIOnly", []JSONOption{ASCIIOnly()}, "\U0001F600", `\ud83d\ude00`},
+		{"ASCIIOnly", []JSONOption{ASCIIOnly()}, "foo\xed\xa0\x80", `foo\ufffd\ufffd\ufffd`},
+		{"both", []JSONOption{HTMLSafe(), ASCIIOnly()}, "<☃>", `\u003c\u2603\u003e`},
+	}
+
+	for _, tt := range tests {
+		enc := newJSONEncoder(tt.opts...)
+		for _, e := range []*jsonEncoder{enc, enc.Clone().(*jsonEncoder)} {
+			e.safeAddString(tt.input)
+			assert.Equal(t, tt.expected, string(e.bytes), "Unexpected output escaping %q in %s mode.", tt.input, tt.desc)
+			e.Free()
+		}
+	}
+}
+
+func TestJSONEscapingModesRoundTrip(t *testing.T) {
+	const input = "<script>alert('é☃\U0001F600')</script>"
+	tests := []struct {
+		opt       JSONOption
+		forbidden string
+	}{
+		{HTMLSafe(), "<"},
+		{ASCIIOnly(), "☃"},
+	}
+	for _, tt := range tests {
+		buf := &testBuffer{}
+		enc := newJSONEncoder(NoTime(), tt.opt)
+		enc.AddString("k", input)
+		require.NoError(t, enc.WriteEntry(buf, input, InfoLevel, epoch), "Unexpected error writing entry.")
+		assert.NotContains(t, buf.String(), tt.forbidden, "Expected message and fields to be escaped.")
+
+		var parsed map[string]string
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &parsed), "Output isn't valid JSON: %s", buf.String())
+		assert.Equal(t, input, parsed["k"], "Expected escaped string to round-trip.")
+		assert.Equal(t, input, parsed["msg"], "Expected escaped message to round-trip.")
+		enc.Free()
+	}
+}
+
+func TestJSONASCIIOnlyObject(t *testing.T) {
+	enc := newJSONEncoder(ASCIIOnly())
+	defer enc.Free()
+	require.NoError(t, enc.AddObject("o", map[string]string{"k": "é😀"}), "Unexpected error adding an object.")
+	assert.Equal(t, `"o":{"k":"\u00e9\ud83d\ude00"}`, string(enc.bytes), "Expected object fields to be ASCII-only.")
+
+	var parsed map[string]map[string]string
+	require.NoError(t, json.Unmarshal([]byte("{"+string(enc.bytes)+"}"), &parsed), "Output isn't valid JSON.")
+	assert.Equal(t, "é😀", parsed["o"]["k"], "Expected escaped object to round-trip.")
+}
+
+func TestJSONSortKeys(t *testing.T) {
+	nested := LogMarshalerFunc(func(kv KeyValue) error {
+		kv.AddString("z", "last")
+		kv.AddMarshaler("m", LogMarshalerFunc(func(kv KeyValue) error {
+			kv.AddInt("b", 2)
+			kv.AddInt("a", 1)
+			return nil
+		}))
+		kv.AddBool("a", true)
+		return nil
+	})
+
+	root := newJSONEncoder(NoTime(), SortKeys())
+	root.AddString("zeta", "z")
+	root.AddString("alpha", "a")
+	parent := root.Clone()
+	parent.AddMarshaler("nested", nested)
+	parent.AddObject("obj", map[string]int{"y": 2, "x": 1})
+	parent.AddInt("dup", 1)
+	parent.AddInt("beta", 2)
+	parent.AddInt("dup", 2)
+
+	for _, enc := range []Encoder{parent, parent.Clone()} {
+		buf := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(buf, "sorted", InfoLevel, epoch), "Unexpected error writing entry.")
+		assert.Equal(
+			t,
+			`{"level":"info","msg":"sorted","alpha":"a","beta":2,"dup":1,"dup":2,`+
+				`"nested":{"a":true,"m":{"a":1,"b":2},"z":"last"},"obj":{"x":1,"y":2},"zeta":"z"}`,
+			buf.Stripped(),
+			"Expected fields to be sorted by key at every level.",
+		)
+	}
+
+	// Writing entries shouldn't reorder the encoder's own buffer, and the root
+	// shouldn't see the child's fields.
+	assertJSON(t, `"zeta":"z","alpha":"a"`, root)
+}
+
+func TestJSONMaxStringLength(t *testing.T) {
+	tests := []struct {
+		desc     string
+		f        func(Encoder)
+		expected string
+	}{
+		{"short string", func(e Encoder) { e.AddString("k", "hello") }, `"k":"hello"`},
+		{"long string", func(e Encoder) { e.AddString("k", "hello world") }, `"k":"hello…(truncated 6 bytes)"`},
+		{"escaped string", func(e Encoder) { e.AddString("k", "a\nb\ncdef") }, `"k":"a\nb\nc…(truncated 3 bytes)"`},
+		{"multi-byte runes", func(e Encoder) { e.AddString("k", "ab☃☃") }, `"k":"ab☃…(truncated 3 bytes)"`},
+		{"base64", func(e Encoder) { Base64("k", []byte("foobar")).AddTo(e) }, `"k":"Zm9vY…(truncated 3 bytes)"`},
+		{"small object", func(e Encoder) { e.AddObject("k", []int{1}) }, `"k":[1]`},
+		{"large object", func(e Encoder) { e.AddObject("k", []int{1, 2, 3, 4}) }, `"k":"[1,2,…(truncated 4 bytes)"`},
+	}
+
+	for _, tt := range tests {
+		enc := newJSONEncoder(MaxStringLength(5), MaxMessageLength(1))
+		tt.f(enc)
+		assertJSON(t, tt.expected, enc)
+		enc.Free()
+	}
+}
+
+func TestJSONMaxMessageLength(t *testing.T) {
+	enc := NewJSONEncoder(NoTime(), MaxMessageLength(5), MaxStringLength(100))
+	buf := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(buf, "hello world", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, `{"level":"info","msg":"hello…(truncated 6 bytes)"}`, buf.Stripped(), "Expected message to be truncated.")
+
+	buf.Reset()
+	require.NoError(t, enc.WriteEntry(buf, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, `{"level":"info","msg":"hello"}`, buf.Stripped(), "Expected short message to be left alone.")
+}
+
+func TestJSONMaxNestingDepth(t *testing.T) {
+	enc := newJSONEncoder(MaxNestingDepth(1))
+	Nest("a", Nest("b", Int("c", 1)), Int("d", 2)).AddTo(enc)
+	assertJSON(t, `"a":{"b":{},"bError":"exceeded maximum nesting depth","d":2}`, enc)
+
+	// Depth shouldn't leak between fields.
+	Nest("e", Int("f", 3)).AddTo(enc)
+	assert.Contains(t, string(enc.bytes), `"e":{"f":3}`, "Expected sibling objects to be encoded.")
+	enc.Free()
+}
+
+func TestJSONMaxEntrySize(t *testing.T) {
+	const limit = 200
+	enc := NewJSONEncoder(NoTime(), MaxEntrySize(limit))
+	enc.AddString("small", "a")
+	enc.AddString("big", strings.Repeat("x", 500))
+	child := enc.Clone()
+	child.AddInt("after", 42)
+
+	buf := &testBuffer{}
+	require.NoError(t, child.WriteEntry(buf, "fits", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.True(t, buf.Len() <= limit, "Expected entry to respect size limit, got %d bytes.", buf.Len())
+	assert.Equal(
+		t,
+		`{"level":"info","msg":"fits","small":"a","after":42,"truncated":"…(truncated 508 bytes)"}`,
+		buf.Stripped(),
+		"Expected large fields to be dropped.",
+	)
+
+	buf.Reset()
+	require.NoError(t, child.WriteEntry(buf, strings.Repeat("\n", 500), InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.True(t, buf.Len() <= limit, "Expected entry to respect size limit, got %d bytes.", buf.Len())
+	var parsed map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &parsed), "Output isn't valid JSON: %s", buf.String())
+	assert.Contains(t, parsed["msg"], "truncated", "Expected message to be truncated.")
+
+	// Entries that fit should be untouched.
+	buf.Reset()
+	enc = NewJSONEncoder(NoTime(), MaxEntrySize(limit))
+	enc.AddString("k", "v")
+	require.NoError(t, enc.WriteEntry(buf, "fits", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, `{"level":"info","msg":"fits","k":"v"}`, buf.Stripped(), "Unexpected output for an entry within the limit.")
+}
+
+func TestJSONMaxEntrySizeSorted(t *testing.T) {
+	enc := NewJSONEncoder(NoTime(), MaxEntrySize(150), SortKeys())
+	enc.AddString("b", "b")
+	enc.AddString("c", strings.Repeat("x", 100))
+	enc.AddMarshaler("a", LogMarshalerFunc(func(kv KeyValue) error {
+		kv.AddInt("z", 1)
+		kv.AddInt("y", 2)
+		return nil
+	}))
+
+	buf := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(buf, "sorted", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(
+		t,
+		`{"level":"info","msg":"sorted","a":{"y":2,"z":1},"b":"b","truncated":"…(truncated 106 bytes)"}`,
+		buf.Stripped(),
+		"Expected sorted output with large fields dropped.",
+	)
+}
+
 func TestJSONOptions(t *testing.T) {
 	root := NewJSONEncoder(
 		MessageKey("the-message"),
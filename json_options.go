@@ -23,11 +23,51 @@ package zap
 import "time"
 
 // JSONOption is used to set options for a JSON encoder. MessageFormatters,
-// TimeFormatters, and LevelFormatters all implement the JSONOption interface.
+// TimeFormatters, LevelFormatters, and NameFormatters all implement the
+// JSONOption interface.
 type JSONOption interface {
 	apply(*jsonEncoder)
 }
 
+type jsonOptionFunc func(*jsonEncoder)
+
+func (opt jsonOptionFunc) apply(enc *jsonEncoder) {
+	opt(enc)
+}
+
+// HTMLSafe escapes '<', '>', and '&' (along with the U+2028 and U+2029 line
+// separators) in all keys and string values, so that encoded entries can be
+// safely embedded in HTML and JavaScript. It's slightly slower than the
+// default escaping.
+func HTMLSafe() JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.htmlSafe = true
+	})
+}
+
+// ASCIIOnly escapes every non-ASCII rune as \uXXXX (using UTF-16 surrogate
+// pairs for runes outside the Basic Multilingual Plane), so that encoded
+// entries contain only 7-bit ASCII. It's slower than the default escaping,
+// which passes valid UTF-8 through unchanged.
+func ASCIIOnly() JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.asciiOnly = true
+	})
+}
+
+// SortKeys emits each entry's fields sorted by key, at every level of nesting,
+// rather than in the order they were added. The message, level, and time are
+// still written first. Sorting makes output easy to compare (e.g., in golden
+// files), but it's considerably slower than the default.
+//
+// Objects added with AddObject are serialized by encoding/json, which sorts
+// map keys but leaves struct fields in declaration order.
+func SortKeys() JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.sortKeys = true
+	})
+}
+
 // A MessageFormatter defines how to convert a log message into a Field.
 // MessageFormatters implement the JSONOption interface.
 type MessageFormatter func(string) Field
@@ -59,6 +99,9 @@ func EpochFormatter(key string) TimeFormatter {
 	})
 }
 
+// _iso8601Layout is an ISO8601 layout with millisecond precision.
+const _iso8601Layout = "2006-01-02T15:04:05.000Z0700"
+
 // RFC3339Formatter encodes the entry time as an RFC3339-formatted string under
 // the provided key.
 func RFC3339Formatter(key string) TimeFormatter {
@@ -67,6 +110,14 @@ func RFC3339Formatter(key string) TimeFormatter {
 	})
 }
 
+// ISO8601Formatter encodes the entry time as an ISO8601-formatted string, with
+// millisecond precision, under the provided key.
+func ISO8601Formatter(key string) TimeFormatter {
+	return TimeFormatter(func(t time.Time) Field {
+		return String(key, t.Format(_iso8601Layout))
+	})
+}
+
 // NoTime drops the entry time altogether. It's often useful in testing, since
 // it removes the need to stub time.Now.
 func NoTime() TimeFormatter {
@@ -90,3 +141,66 @@ func LevelString(key string) LevelFormatter {
 		return String(key, l.String())
 	})
 }
+
+// A NameFormatter defines how to convert the name of a Named logger into a
+// Field. It's only called for loggers with names. NameFormatters implement the
+// JSONOption interface.
+type NameFormatter func(string) Field
+
+func (nf NameFormatter) apply(enc *jsonEncoder) {
+	enc.nameF = nf
+}
+
+// NameKey encodes logger names under the provided key.
+func NameKey(key string) NameFormatter {
+	return NameFormatter(func(name string) Field {
+		return String(key, name)
+	})
+}
+
+// MaxMessageLength truncates log messages longer than n bytes, appending a
+// marker like "…(truncated 42 bytes)". Truncation never splits a UTF-8
+// sequence. Non-positive values (the default) disable truncation.
+func MaxMessageLength(n int) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.maxMessage = n
+	})
+}
+
+// MaxStringLength truncates string fields longer than n bytes, appending the
+// same marker as MaxMessageLength. It applies to any field added with
+// AddString (including Base64 and Stringer fields), and Object fields whose
+// JSON representation is too long are truncated and encoded as strings.
+// Non-positive values (the default) disable truncation.
+func MaxStringLength(n int) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.maxString = n
+	})
+}
+
+// MaxNestingDepth limits how deeply LogMarshalers (including Nest fields) may
+// nest objects. Objects nested more than n levels deep are encoded as {}, and
+// the error is recorded alongside them. Non-positive values (the default)
+// allow unlimited nesting.
+func MaxNestingDepth(n int) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.maxDepth = n
+	})
+}
+
+// MaxEntrySize limits the size of each serialized entry (including the
+// trailing newline) to n bytes. Context fields that don't fit are dropped,
+// and the number of bytes dropped is recorded under the "truncated" key. If
+// the level, time, and message alone exceed the limit, the message is
+// truncated as well. Since the limit can't be respected if it's too small to
+// hold a minimal entry, it should be at least a few hundred bytes.
+// Non-positive values (the default) disable the limit.
+//
+// Unlike the other limits, which the text and template encoders offer as
+// TextMaxMessageLength, TemplateMaxStringLength, and so on, MaxEntrySize is
+// only supported by the JSON encoder.
+func MaxEntrySize(n int) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.maxEntry = n
+	})
+}
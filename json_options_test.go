@@ -51,6 +51,7 @@ func TestTimeFormatters(t *testing.T) {
 	}{
 		{"EpochFormatter", EpochFormatter("the-time"), Float64("the-time", 0)},
 		{"RFC3339", RFC3339Formatter("ts"), String("ts", "1970-01-01T00:00:00Z")},
+		{"ISO8601", ISO8601Formatter("ts"), String("ts", "1970-01-01T00:00:00.000Z")},
 		{"NoTime", NoTime(), Skip()},
 		{"Default", defaultTimeF, Float64("ts", 0)},
 	}
@@ -75,3 +76,18 @@ func TestLevelFormatters(t *testing.T) {
 		assert.Equal(t, tt.expected, tt.formatter(lvl), "Unexpected output from LevelFormatter %s.", tt.name)
 	}
 }
+
+func TestNameFormatters(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter NameFormatter
+		expected  Field
+	}{
+		{"NameKey", NameKey("the-name"), String("the-name", "api.http")},
+		{"Default", defaultNameF, String("logger", "api.http")},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.formatter("api.http"), "Unexpected output from NameFormatter %s.", tt.name)
+	}
+}
@@ -178,3 +178,29 @@ func (lvl AtomicLevel) Level() Level {
 func (lvl AtomicLevel) SetLevel(l Level) {
 	lvl.l.Store(int32(l))
 }
+
+// MarshalText marshals the current level to text, like Level.MarshalText. A
+// zero AtomicLevel marshals as InfoLevel, the level Config.Build gives it.
+func (lvl AtomicLevel) MarshalText() ([]byte, error) {
+	l := InfoLevel
+	if lvl.l != nil {
+		l = lvl.Level()
+	}
+	return l.MarshalText()
+}
+
+// UnmarshalText parses text like Level.UnmarshalText and sets the level. If
+// the AtomicLevel is a zero value, it's initialized first, so AtomicLevels can
+// be embedded in configuration structs (see Config).
+func (lvl *AtomicLevel) UnmarshalText(text []byte) error {
+	var l Level
+	if err := l.UnmarshalText(text); err != nil {
+		return err
+	}
+	if lvl.l == nil {
+		lvl.l = atomic.NewInt32(int32(l))
+		return nil
+	}
+	lvl.SetLevel(l)
+	return nil
+}
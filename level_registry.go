@@ -0,0 +1,263 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/uber-go/atomic"
+)
+
+// A LevelRegistry manages the levels of a family of Named loggers. Passed as
+// an option to a root logger, it assigns each descendant a level based on its
+// dotted name, and changes to its rules take effect immediately, without
+// rebuilding any loggers.
+//
+// Rules are keyed by pattern. A pattern without glob metacharacters names a
+// single logger (e.g., "db.pool"), and it's inherited by that logger's
+// descendants. Patterns with metacharacters are matched against whole names
+// using path.Match, where '*' matches any sequence of characters, including
+// periods; "db.*" matches "db.pool" and "db.pool.conn", but not "db".
+//
+// A logger's effective level comes from, in order of precedence:
+//   1. a rule naming it exactly,
+//   2. the longest glob rule matching its name,
+//   3. its parent's effective level (so "db" covers "db.pool"), and
+//   4. the registry's default level.
+type LevelRegistry struct {
+	dflt AtomicLevel
+
+	mu      sync.Mutex // guards everything below
+	exact   map[string]Level
+	globs   map[string]Level
+	loggers map[string]*registeredLevel
+}
+
+// NewLevelRegistry creates a registry whose loggers use the given level unless
+// a rule says otherwise.
+func NewLevelRegistry(dflt Level) *LevelRegistry {
+	lvl := DynamicLevel()
+	lvl.SetLevel(dflt)
+	return newLevelRegistry(lvl)
+}
+
+// newLevelRegistry creates a registry whose default level is the supplied
+// AtomicLevel, so changing either changes both.
+func newLevelRegistry(dflt AtomicLevel) *LevelRegistry {
+	return &LevelRegistry{
+		dflt:    dflt,
+		exact:   make(map[string]Level),
+		globs:   make(map[string]Level),
+		loggers: make(map[string]*registeredLevel),
+	}
+}
+
+// _defaultRegisteredLevel marks registeredLevels that no rule applies to, which
+// read the registry's default level when they're checked.
+const _defaultRegisteredLevel = int32(invalidLevel)
+
+// registeredLevel is the LevelEnabler of a single named logger. The registry
+// recomputes its level whenever the rules change.
+type registeredLevel struct {
+	reg *LevelRegistry
+	l   *atomic.Int32
+}
+
+func (rl *registeredLevel) Enabled(lvl Level) bool {
+	return rl.level().Enabled(lvl)
+}
+
+func (rl *registeredLevel) level() Level {
+	if l := rl.l.Load(); l != _defaultRegisteredLevel {
+		return Level(l)
+	}
+	return rl.reg.dflt.Level()
+}
+
+func (rl *registeredLevel) forName(name string) LevelEnabler {
+	return rl.reg.forName(name)
+}
+
+// nameScopedEnabler is implemented by LevelEnablers whose decisions depend on
+// the logger's name. Meta.Named uses it to re-scope the enabler.
+type nameScopedEnabler interface {
+	forName(string) LevelEnabler
+}
+
+func (r *LevelRegistry) apply(m *Meta) {
+	m.LevelEnabler = r.forName(m.Name)
+}
+
+// Enabled reports whether the given level is enabled for unnamed loggers.
+func (r *LevelRegistry) Enabled(lvl Level) bool {
+	return r.Level("").Enabled(lvl)
+}
+
+func (r *LevelRegistry) forName(name string) LevelEnabler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rl, ok := r.loggers[name]; ok {
+		return rl
+	}
+	rl := &registeredLevel{reg: r, l: atomic.NewInt32(r.rule(name))}
+	r.loggers[name] = rl
+	return rl
+}
+
+// Level returns the effective level for loggers with the given name.
+func (r *LevelRegistry) Level(name string) Level {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.effective(name)
+}
+
+// DefaultLevel returns the level of loggers that no rule applies to.
+func (r *LevelRegistry) DefaultLevel() Level {
+	return r.dflt.Level()
+}
+
+// SetDefaultLevel changes the level of loggers that no rule applies to.
+func (r *LevelRegistry) SetDefaultLevel(lvl Level) {
+	r.dflt.SetLevel(lvl)
+}
+
+// SetLevel adds or replaces the rule for the given pattern. It returns an error
+// if the pattern is malformed.
+func (r *LevelRegistry) SetLevel(pattern string, lvl Level) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid logger name pattern %q: %v", pattern, err)
+	}
+	r.mu.Lock()
+	if isGlob(pattern) {
+		r.globs[pattern] = lvl
+	} else {
+		r.exact[pattern] = lvl
+	}
+	r.refresh()
+	r.mu.Unlock()
+	return nil
+}
+
+// ClearLevel removes the rule for the given pattern, reporting whether there
+// was one.
+func (r *LevelRegistry) ClearLevel(pattern string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rules := r.exact
+	if isGlob(pattern) {
+		rules = r.globs
+	}
+	if _, ok := rules[pattern]; !ok {
+		return false
+	}
+	delete(rules, pattern)
+	r.refresh()
+	return true
+}
+
+// Rules returns a copy of the registry's rules, keyed by pattern.
+func (r *LevelRegistry) Rules() map[string]Level {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rules := make(map[string]Level, len(r.exact)+len(r.globs))
+	for p, lvl := range r.exact {
+		rules[p] = lvl
+	}
+	for p, lvl := range r.globs {
+		rules[p] = lvl
+	}
+	return rules
+}
+
+// Loggers returns the effective levels of all the loggers created with the
+// registry so far, keyed by name. The root logger's name is empty.
+func (r *LevelRegistry) Loggers() map[string]Level {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	loggers := make(map[string]Level, len(r.loggers))
+	for name, rl := range r.loggers {
+		loggers[name] = rl.level()
+	}
+	return loggers
+}
+
+// refresh recomputes the level of every known logger. The caller must hold
+// the lock.
+func (r *LevelRegistry) refresh() {
+	for name, rl := range r.loggers {
+		rl.l.Store(r.rule(name))
+	}
+}
+
+// effective computes a logger's level from the rules. The caller must hold the
+// lock.
+func (r *LevelRegistry) effective(name string) Level {
+	if l := r.rule(name); l != _defaultRegisteredLevel {
+		return Level(l)
+	}
+	return r.dflt.Level()
+}
+
+// rule finds the level of the rule that applies to a logger, or returns
+// _defaultRegisteredLevel if none does. The caller must hold the lock.
+func (r *LevelRegistry) rule(name string) int32 {
+	for {
+		if lvl, ok := r.exact[name]; ok {
+			return int32(lvl)
+		}
+		if lvl, ok := r.matchGlob(name); ok {
+			return int32(lvl)
+		}
+		if name == "" {
+			return _defaultRegisteredLevel
+		}
+		if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+			name = name[:idx]
+		} else {
+			name = ""
+		}
+	}
+}
+
+// matchGlob finds the longest glob rule matching the name, breaking ties by
+// comparing the patterns lexically.
+func (r *LevelRegistry) matchGlob(name string) (Level, bool) {
+	if len(r.globs) == 0 {
+		return 0, false
+	}
+	best, found := "", false
+	for pattern := range r.globs {
+		if ok, _ := path.Match(pattern, name); !ok {
+			continue
+		}
+		if !found || len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+			best, found = pattern, true
+		}
+	}
+	return r.globs[best], found
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelRegistryEffectiveLevels(t *testing.T) {
+	reg := NewLevelRegistry(InfoLevel)
+	assert.NoError(t, reg.SetLevel("db", WarnLevel), "Unexpected error setting an exact rule.")
+	assert.NoError(t, reg.SetLevel("db.*", DebugLevel), "Unexpected error setting a glob rule.")
+	assert.NoError(t, reg.SetLevel("db.pool.*", ErrorLevel), "Unexpected error setting a glob rule.")
+	assert.NoError(t, reg.SetLevel("api.http", DebugLevel), "Unexpected error setting an exact rule.")
+	assert.NoError(t, reg.SetLevel("db.cache", InfoLevel), "Unexpected error setting an exact rule.")
+
+	tests := []struct {
+		name     string
+		expected Level
+	}{
+		{"", InfoLevel},
+		{"other", InfoLevel},
+		{"db", WarnLevel},
+		{"db.pool", DebugLevel},
+		{"db.pool.conn", ErrorLevel},
+		{"db.cache", InfoLevel},
+		{"api", InfoLevel},
+		{"api.http", DebugLevel},
+		{"api.http.router", DebugLevel},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, reg.Level(tt.name), "Unexpected effective level for %q.", tt.name)
+	}
+
+	assert.True(t, reg.ClearLevel("db.*"), "Expected to clear an existing rule.")
+	assert.False(t, reg.ClearLevel("db.*"), "Expected clearing a missing rule to report false.")
+	assert.Equal(t, WarnLevel, reg.Level("db.pool"), "Expected to inherit from the parent once the glob is cleared.")
+
+	reg.SetDefaultLevel(ErrorLevel)
+	assert.Equal(t, ErrorLevel, reg.DefaultLevel(), "Unexpected default level.")
+	assert.Equal(t, ErrorLevel, reg.Level("other"), "Expected unmatched names to use the new default.")
+	assert.Equal(t, map[string]Level{
+		"db":        WarnLevel,
+		"db.pool.*": ErrorLevel,
+		"api.http":  DebugLevel,
+		"db.cache":  InfoLevel,
+	}, reg.Rules(), "Unexpected rules.")
+}
+
+func TestLevelRegistryInvalidPattern(t *testing.T) {
+	reg := NewLevelRegistry(InfoLevel)
+	assert.Error(t, reg.SetLevel("db.[", DebugLevel), "Expected an error for a malformed pattern.")
+	assert.Empty(t, reg.Rules(), "Expected malformed patterns to be ignored.")
+}
+
+func TestLevelRegistryUpdatesLoggers(t *testing.T) {
+	reg := NewLevelRegistry(InfoLevel)
+	buf := &testBuffer{}
+	root := New(newJSONEncoder(NoTime()), reg, Output(buf))
+	db := root.Named("db")
+	pool := db.Named("pool").With(String("k", "v"))
+
+	pool.Debug("dropped")
+	assert.NoError(t, reg.SetLevel("db.*", DebugLevel), "Unexpected error setting a rule.")
+	pool.Debug("pool debug")
+	db.Debug("db dropped")
+	root.Debug("root dropped")
+	reg.SetDefaultLevel(DebugLevel)
+	root.Debug("root debug")
+
+	assert.Equal(t, []string{
+		`{"level":"debug","logger":"db.pool","msg":"pool debug","k":"v"}`,
+		`{"level":"debug","msg":"root debug"}`,
+	}, buf.Lines(), "Expected rule changes to apply to existing loggers.")
+	assert.Equal(t, map[string]Level{
+		"":        DebugLevel,
+		"db":      DebugLevel,
+		"db.pool": DebugLevel,
+	}, reg.Loggers(), "Unexpected known loggers.")
+	assert.True(t, reg.Enabled(DebugLevel), "Expected the registry to report the root level.")
+}
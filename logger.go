@@ -22,7 +22,6 @@ package zap
 
 import (
 	"os"
-	"time"
 )
 
 // For tests.
@@ -34,6 +33,11 @@ type Logger interface {
 	// Create a child logger, and optionally add some context to that logger.
 	With(...Field) Logger
 
+	// Create a child logger with the given name segment appended to the
+	// logger's name. Names are dotted paths, so a logger named "api" creates
+	// a child named "api.http" with Named("http").
+	Named(string) Logger
+
 	// Check returns a CheckedMessage if logging a message at the specified level
 	// is enabled. It's a completely optional optimization; in high-performance
 	// applications, Check can help avoid allocating a slice to hold fields.
@@ -56,6 +60,10 @@ type Logger interface {
 	DPanic(string, ...Field)
 	Panic(string, ...Field)
 	Fatal(string, ...Field)
+
+	// Sync flushes any buffered log entries. Applications should take care
+	// to call Sync before exiting.
+	Sync() error
 }
 
 type logger struct{ Meta }
@@ -80,6 +88,16 @@ func (log *logger) With(fields ...Field) Logger {
 	return clone
 }
 
+func (log *logger) Named(name string) Logger {
+	return &logger{
+		Meta: log.Meta.Named(name),
+	}
+}
+
+func (log *logger) Sync() error {
+	return log.Output.Sync()
+}
+
 func (log *logger) Check(lvl Level, msg string) *CheckedMessage {
 	return log.Meta.Check(log, lvl, msg)
 }
@@ -107,18 +125,33 @@ func (log *logger) Error(msg string, fields ...Field) {
 func (log *logger) DPanic(msg string, fields ...Field) {
 	log.log(DPanicLevel, msg, fields)
 	if log.Development {
-		panic(msg)
+		panic(log.Meta.PanicValue(DPanicLevel, msg, fields))
 	}
 }
 
 func (log *logger) Panic(msg string, fields ...Field) {
 	log.log(PanicLevel, msg, fields)
-	panic(msg)
+	panic(log.Meta.PanicValue(PanicLevel, msg, fields))
 }
 
 func (log *logger) Fatal(msg string, fields ...Field) {
 	log.log(FatalLevel, msg, fields)
-	_exit(1)
+	log.Meta.Exit()
+}
+
+// catchDPanic calls the logger's DPanic method, recovering and returning the
+// value of any resulting panic. Wrappers that fan out to several loggers use
+// it to deliver a DPanic message to all of them before panicking.
+func catchDPanic(log Logger, msg string, fields []Field) (recovered interface{}, panicked bool) {
+	panicked = true
+	defer func() {
+		if panicked {
+			recovered = recover()
+		}
+	}()
+	log.DPanic(msg, fields...)
+	panicked = false
+	return nil, false
 }
 
 func (log *logger) log(lvl Level, msg string, fields []Field) {
@@ -126,9 +159,9 @@ func (log *logger) log(lvl Level, msg string, fields []Field) {
 		return
 	}
 
-	t := time.Now().UTC()
+	t := log.Meta.Now()
 	if err := log.Encode(log.Output, t, lvl, msg, fields); err != nil {
-		log.InternalError("encoder", err)
+		log.InternalError(encoderCause, err)
 	}
 
 	if lvl > ErrorLevel {
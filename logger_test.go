@@ -21,16 +21,20 @@
 package zap
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/uber-go/zap/spywrite"
+	"github.com/uber-go/zap/testutils"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func opts(opts ...Option) []Option {
@@ -177,6 +181,27 @@ func TestJSONLoggerWith(t *testing.T) {
 	})
 }
 
+func TestJSONLoggerNamed(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		api := logger.Named("api")
+		api.Named("http").With(String("path", "/")).Info("request")
+		api.Named("").Info("empty segment")
+		api.Info("parent")
+		logger.Info("root")
+		assert.Equal(t, []string{
+			`{"level":"info","logger":"api.http","msg":"request","path":"/"}`,
+			`{"level":"info","logger":"api","msg":"empty segment"}`,
+			`{"level":"info","logger":"api","msg":"parent"}`,
+			`{"level":"info","msg":"root"}`,
+		}, buf.Lines(), "Unexpected output from named loggers.")
+	})
+
+	buf := &testBuffer{}
+	logger := New(newJSONEncoder(NoTime(), NameKey("component")), Output(buf))
+	logger.Named("db").Info("query")
+	assert.Equal(t, `{"level":"info","component":"db","msg":"query"}`, buf.Stripped(), "Expected a custom name key.")
+}
+
 func TestJSONLoggerLog(t *testing.T) {
 	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
 		logger.Log(DebugLevel, "foo")
@@ -364,10 +389,24 @@ func TestJSONLoggerWriteEntryFailure(t *testing.T) {
 
 	logger.Info("foo")
 	// Should log the error.
-	assert.Regexp(t, `encoder error: failed`, errBuf.Stripped(), "Expected to log the error to the error output.")
+	assert.Regexp(t, `"cause":"write","error":"failed","suppressed":0`, errBuf.Stripped(), "Expected to log the error to the error output.")
 	assert.True(t, errSink.Called(), "Expected logging an internal error to call Sync the error sink.")
 }
 
+func TestLoggerInternalErrorUsesClock(t *testing.T) {
+	errBuf := &testBuffer{}
+	clock := testutils.NewFakeClock(time.Unix(0, 0))
+	logger := New(
+		newJSONEncoder(),
+		Output(AddSync(spywrite.FailWriter{})),
+		ErrorOutput(errBuf),
+		WithClock(clock),
+	)
+
+	logger.Info("foo")
+	assert.Equal(t, `{"time":"1970-01-01T00:00:00Z","cause":"write","error":"failed","suppressed":0}`, errBuf.Stripped(), "Expected internal errors to be stamped by the configured clock.")
+}
+
 func TestJSONLoggerSyncsOutput(t *testing.T) {
 	sink := &spywrite.WriteSyncer{Writer: ioutil.Discard}
 	logger := New(newJSONEncoder(), DebugLevel, Output(sink))
@@ -379,6 +418,16 @@ func TestJSONLoggerSyncsOutput(t *testing.T) {
 	assert.True(t, sink.Called(), "Expected logging at panic level to Sync underlying WriteSyncer.")
 }
 
+func TestJSONLoggerSync(t *testing.T) {
+	sink := &spywrite.WriteSyncer{Writer: ioutil.Discard}
+	logger := New(newJSONEncoder(), Output(sink))
+	assert.NoError(t, logger.Sync(), "Unexpected error syncing logger.")
+	assert.True(t, sink.Called(), "Expected Sync to sync the output.")
+
+	sink.SetError(errors.New("sync failed"))
+	assert.Error(t, logger.With(String("foo", "bar")).Sync(), "Expected Sync to return the output's error.")
+}
+
 func TestLoggerConcurrent(t *testing.T) {
 	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
 		child := logger.With(String("foo", "bar"))
@@ -410,3 +459,187 @@ func runConcurrently(goroutines, iterations int, wg *sync.WaitGroup, f func()) {
 		}()
 	}
 }
+
+func TestLoggerExitOptions(t *testing.T) {
+	var codes []int
+	withJSONLogger(t, opts(ExitFunc(func(code int) { codes = append(codes, code) }), ExitCode(42)), func(logger Logger, buf *testBuffer) {
+		logger.Fatal("foo")
+		logger.Check(FatalLevel, "bar").Write()
+		assert.Equal(t, []string{`{"level":"fatal","msg":"foo"}`, `{"level":"fatal","msg":"bar"}`}, buf.Lines(), "Unexpected output from Fatal.")
+	})
+	assert.Equal(t, []int{42, 42}, codes, "Expected Fatal to use the configured exit function and code.")
+}
+
+func TestLoggerShutdownHooks(t *testing.T) {
+	var (
+		events []string
+		code   int
+	)
+	out := &spywrite.WriteSyncer{Writer: ioutil.Discard}
+	logger := New(
+		NewJSONEncoder(),
+		Output(out),
+		ExitFunc(func(c int) {
+			events = append(events, "exit")
+			code = c
+		}),
+		OnShutdown(func() {
+			assert.True(t, out.Called(), "Expected outputs to be synced before shutdown hooks run.")
+			events = append(events, "first")
+		}),
+		OnShutdown(func() { events = append(events, "second") }),
+	)
+	logger.Fatal("shutting down")
+	assert.Equal(t, []string{"first", "second", "exit"}, events, "Expected hooks to run in order before exiting.")
+	assert.Equal(t, 1, code, "Unexpected exit code.")
+}
+
+// hiddenMeta hides all of a Logger's methods except those of the Logger
+// interface, like a third-party wrapper might.
+type hiddenMeta struct {
+	Logger
+}
+
+func TestCompositeLoggersExit(t *testing.T) {
+	var (
+		hooks []string
+		exits []string
+		codes []int
+	)
+	hooked := func(name string, options ...Option) Logger {
+		options = append(options, DiscardOutput, OnShutdown(func() { hooks = append(hooks, name) }))
+		return New(NewJSONEncoder(), options...)
+	}
+	exitFunc := func(name string) Option {
+		return ExitFunc(func(code int) {
+			exits = append(exits, name)
+			codes = append(codes, code)
+		})
+	}
+
+	tests := []struct {
+		desc  string
+		log   Logger
+		hooks []string
+		exit  string
+		code  int
+	}{
+		{
+			desc:  "exit function on the second sub-logger",
+			log:   Tee(hooked("a"), hooked("b", exitFunc("b"), ExitCode(3))),
+			hooks: []string{"a", "b"},
+			exit:  "b",
+			code:  3,
+		},
+		{
+			desc:  "first exit function wins",
+			log:   Tee(hooked("a", ExitCode(2)), hooked("b", exitFunc("b"), ExitCode(3)), hooked("c", exitFunc("c"))),
+			hooks: []string{"a", "b", "c"},
+			exit:  "b",
+			code:  3,
+		},
+		{
+			desc:  "sub-logger without Meta",
+			log:   Tee(hiddenMeta{hooked("a")}, hooked("b", exitFunc("b"))),
+			hooks: []string{"b"},
+			exit:  "b",
+			code:  1,
+		},
+		{
+			desc:  "wrapped sub-loggers",
+			log:   Tee(Sample(hooked("a"), time.Minute, 1, 1), Router(nil, Route{Logger: hooked("b")}), Tee(hooked("c"), hooked("d", exitFunc("d"), ExitCode(4)))),
+			hooks: []string{"a", "b", "c", "d"},
+			exit:  "d",
+			code:  4,
+		},
+		{
+			desc:  "filter",
+			log:   Filter(LeveledLogger{FatalLevel, Sample(hooked("a", exitFunc("a"), ExitCode(5)), time.Minute, 1, 1)}),
+			hooks: []string{"a"},
+			exit:  "a",
+			code:  5,
+		},
+	}
+
+	for _, tt := range tests {
+		hooks, exits, codes = nil, nil, nil
+		tt.log.Fatal("foo")
+		assert.Equal(t, tt.hooks, hooks, "Unexpected shutdown hooks run for %s.", tt.desc)
+		assert.Equal(t, []string{tt.exit}, exits, "Unexpected exit function for %s.", tt.desc)
+		assert.Equal(t, []int{tt.code}, codes, "Unexpected exit code for %s.", tt.desc)
+	}
+}
+
+func TestCompositeLoggersExitWithoutExitFunc(t *testing.T) {
+	stub := stubExit()
+	defer stub.Unstub()
+	Tee(New(NewJSONEncoder(), DiscardOutput, ExitCode(3)), New(NewJSONEncoder(), DiscardOutput)).Fatal("foo")
+	stub.AssertStatus(t, 3)
+
+	stub = stubExit()
+	Tee(hiddenMeta{New(NewJSONEncoder(), DiscardOutput, ExitCode(3))}, hiddenMeta{New(NewJSONEncoder(), DiscardOutput)}).Fatal("foo")
+	stub.AssertStatus(t, 1)
+}
+
+func TestLoggerShutdownHookFailures(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		reports []ErrorReport
+		ran     bool
+		exited  bool
+		release = make(chan struct{})
+	)
+	defer close(release)
+	logger := New(
+		NewJSONEncoder(),
+		DiscardOutput,
+		ExitFunc(func(int) { exited = true }),
+		ShutdownTimeout(10*time.Millisecond),
+		OnInternalError(func(r ErrorReport) {
+			mu.Lock()
+			reports = append(reports, r)
+			mu.Unlock()
+		}),
+		NewErrorTracker(0),
+		OnShutdown(
+			func() { panic("oh no") },
+			func() {
+				mu.Lock()
+				ran = true
+				mu.Unlock()
+			},
+			func() { <-release },
+		),
+	)
+	logger.Fatal("shutting down")
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.True(t, exited, "Expected to exit despite failing hooks.")
+	assert.True(t, ran, "Expected hooks after a panicking hook to run.")
+	require.Equal(t, 2, len(reports), "Expected a report for each failure.")
+	assert.Equal(t, "shutdown hook panicked: oh no", reports[0].Err.Error(), "Unexpected report for a panicking hook.")
+	assert.Equal(t, "shutdown hooks didn't finish within 10ms", reports[1].Err.Error(), "Unexpected report for a timeout.")
+	for _, r := range reports {
+		assert.Equal(t, "shutdown", r.Cause, "Unexpected cause.")
+	}
+}
+
+func TestLoggerPanicValue(t *testing.T) {
+	type custom struct {
+		msg    string
+		fields int
+	}
+	withJSONLogger(t, opts(PanicValueFunc(func(lvl Level, msg string, fields []Field) interface{} {
+		assert.Equal(t, PanicLevel, lvl, "Unexpected level passed to PanicValueFunc function.")
+		return custom{msg, len(fields)}
+	})), func(logger Logger, buf *testBuffer) {
+		recovered := func(f func()) (r interface{}) {
+			defer func() { r = recover() }()
+			f()
+			return nil
+		}
+		assert.Equal(t, custom{"foo", 1}, recovered(func() { logger.Panic("foo", Int("n", 1)) }), "Unexpected panic value.")
+		assert.Equal(t, custom{"bar", 0}, recovered(func() { logger.Check(PanicLevel, "bar").Write() }), "Unexpected panic value from Check.")
+	})
+}
@@ -21,7 +21,6 @@
 package zap
 
 import (
-	"fmt"
 	"io"
 	"os"
 	"sync"
@@ -47,17 +46,36 @@ type Meta struct {
 	Hooks       []Hook
 	Output      WriteSyncer
 	ErrorOutput WriteSyncer
+	Clock       Clock
+	Location    *time.Location
+	Name        string
+
+	ErrorTracker *ErrorTracker
+	ErrorHandler ErrorHandler
+
+	ExitFunc        func(code int)
+	ExitCode        int
+	ShutdownHooks   []func()
+	ShutdownTimeout time.Duration
+	PanicValueFunc  func(lvl Level, msg string, fields []Field) interface{}
 }
 
 // MakeMeta returns a new meta struct with sensible defaults: logging at
-// InfoLevel, development mode off, and writing to standard error and standard
-// out.
+// InfoLevel, development mode off, writing to standard error and standard out,
+// timestamping entries with the system clock in UTC, reporting each cause of
+// internal errors at most once per second, exiting with status 1 on Fatal,
+// and giving shutdown hooks five seconds to run.
 func MakeMeta(enc Encoder, options ...Option) Meta {
 	m := Meta{
 		Encoder:      enc,
 		Output:       newLockedWriteSyncer(os.Stdout),
 		ErrorOutput:  newLockedWriteSyncer(os.Stderr),
 		LevelEnabler: InfoLevel,
+		Clock:        systemClock{},
+		ErrorTracker: NewErrorTracker(_defaultErrorInterval),
+
+		ExitCode:        1,
+		ShutdownTimeout: _defaultShutdownTimeout,
 	}
 	for _, opt := range options {
 		opt.apply(&m)
@@ -72,6 +90,39 @@ func (m Meta) Clone() Meta {
 	return m
 }
 
+// Named creates a copy of the meta struct whose name has the given segment
+// appended, separated from any existing name by a period. Like Clone, it
+// deep-copies the encoder, which then includes the full name in each entry
+// (if it supports names). If the level comes from a LevelRegistry, the copy
+// uses the level registered for the new name. Empty segments leave the name
+// unchanged.
+func (m Meta) Named(name string) Meta {
+	if m.Encoder != nil {
+		m.Encoder = m.Encoder.Clone()
+	}
+	if name == "" {
+		return m
+	}
+	if m.Name == "" {
+		m.Name = name
+	} else {
+		m.Name = m.Name + "." + name
+	}
+	if enc, ok := m.Encoder.(nameSetter); ok {
+		enc.setName(m.Name)
+	}
+	if enabler, ok := m.LevelEnabler.(nameScopedEnabler); ok {
+		m.LevelEnabler = enabler.forName(m.Name)
+	}
+	return m
+}
+
+// LoggerName returns the logger's name, as built up by calls to Named. It lets
+// wrappers like zwrap.Filter see the name of the logger they wrap.
+func (m Meta) LoggerName() string {
+	return m.Name
+}
+
 // Check returns a CheckedMessage logging the given message is Enabled, nil
 // otherwise.
 func (m Meta) Check(log Logger, lvl Level, msg string) *CheckedMessage {
@@ -80,6 +131,11 @@ func (m Meta) Check(log Logger, lvl Level, msg string) *CheckedMessage {
 		// Panic and Fatal should always cause a panic/exit, even if the level
 		// is disabled.
 		break
+	case DPanicLevel:
+		// Likewise, DPanic should always panic in development.
+		if !m.Development && !m.Enabled(lvl) {
+			return nil
+		}
 	default:
 		if !m.Enabled(lvl) {
 			return nil
@@ -88,33 +144,77 @@ func (m Meta) Check(log Logger, lvl Level, msg string) *CheckedMessage {
 	return NewCheckedMessage(log, lvl, msg)
 }
 
-// InternalError prints an internal error message to the configured
-// ErrorOutput. This method should only be used to report internal logger
-// problems and should not be used to report user-caused problems.
+// Now returns the current time according to the Meta's Clock, converted to
+// its Location. A nil Clock reads the system clock, and a nil Location
+// reports times in UTC.
+func (m Meta) Now() time.Time {
+	var t time.Time
+	if m.Clock == nil {
+		t = time.Now()
+	} else {
+		t = m.Clock.Now()
+	}
+	if m.Location == nil {
+		return t.UTC()
+	}
+	return t.In(m.Location)
+}
+
+// InternalError reports an internal error. Errors are counted and rate-limited
+// by the ErrorTracker (if any), then passed to the ErrorHandler or, if there
+// isn't one, written to the ErrorOutput as a line of JSON. Errors from writing
+// entries are reported with the cause "write" or "short write" rather than
+// the supplied cause. This method should only be used to report internal
+// logger problems and should not be used to report user-caused problems.
 func (m Meta) InternalError(cause string, err error) {
-	fmt.Fprintf(m.ErrorOutput, "%v %s error: %v\n", time.Now().UTC(), cause, err)
-	m.ErrorOutput.Sync()
+	r := ErrorReport{Time: m.Now(), Cause: errorCause(cause, err), Err: err}
+	if m.ErrorTracker != nil {
+		suppressed, ok := m.ErrorTracker.track(r.Cause)
+		if !ok {
+			return
+		}
+		r.Suppressed = suppressed
+	}
+	if m.ErrorHandler != nil {
+		m.ErrorHandler(r)
+		return
+	}
+	writeErrorReport(m.ErrorOutput, r)
 }
 
 // Encode runs any Hook functions and then writes an encoded log entry to the
-// given io.Writer, returning any error.
+// given io.Writer, returning any error. If a hook drops the entry, nothing is
+// written. When there are no hooks, the fields are encoded directly.
 func (m Meta) Encode(w io.Writer, t time.Time, lvl Level, msg string, fields []Field) error {
-	enc := m.Encoder.Clone()
-	addFields(enc, fields)
-	if len(m.Hooks) >= 0 {
+	if len(m.Hooks) > 0 {
 		entry := _entryPool.Get().(*Entry)
 		entry.Level = lvl
 		entry.Message = msg
 		entry.Time = t
-		entry.enc = enc
+		// Copy the fields so that hooks can't modify the caller's slice.
+		entry.Fields = append(entry.Fields[:0], fields...)
 		for _, hook := range m.Hooks {
 			if err := hook(entry); err != nil {
-				m.InternalError("hook", err)
+				m.InternalError(hookCause, err)
+			}
+			if entry.dropped {
+				break
 			}
 		}
-		msg, enc = entry.Message, entry.enc
+		var err error
+		if !entry.dropped {
+			err = m.write(w, entry.Time, entry.Level, entry.Message, entry.Fields)
+		}
+		entry.reset()
 		_entryPool.Put(entry)
+		return err
 	}
+	return m.write(w, t, lvl, msg, fields)
+}
+
+func (m Meta) write(w io.Writer, t time.Time, lvl Level, msg string, fields []Field) error {
+	enc := m.Encoder.Clone()
+	addFields(enc, fields)
 	err := enc.WriteEntry(w, msg, lvl, t)
 	enc.Free()
 	return err
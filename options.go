@@ -20,6 +20,8 @@
 
 package zap
 
+import "time"
+
 // Option is used to set options for the logger.
 type Option interface {
 	apply(*Meta)
@@ -61,6 +63,74 @@ func ErrorOutput(w WriteSyncer) Option {
 	})
 }
 
+// WithClock sets the Clock used to timestamp log entries and internal errors.
+// It's most useful in tests, which can supply a fake clock instead of
+// discarding timestamps.
+func WithClock(clock Clock) Option {
+	return optionFunc(func(m *Meta) {
+		m.Clock = clock
+	})
+}
+
+// TimeZone converts timestamps to the given location before they're encoded.
+// By default, timestamps are in UTC.
+func TimeZone(loc *time.Location) Option {
+	return optionFunc(func(m *Meta) {
+		m.Location = loc
+	})
+}
+
+// OnInternalError sets a function to receive reports of internal errors in
+// place of the logger's ErrorOutput. See ErrorTracker for details of rate
+// limiting.
+func OnInternalError(h ErrorHandler) Option {
+	return optionFunc(func(m *Meta) {
+		m.ErrorHandler = h
+	})
+}
+
+// ExitFunc sets the function that Fatal calls to end the process, in place of
+// os.Exit. It's particularly useful in tests.
+func ExitFunc(f func(code int)) Option {
+	return optionFunc(func(m *Meta) {
+		m.ExitFunc = f
+	})
+}
+
+// ExitCode sets the status that Fatal exits with. The default is 1.
+func ExitCode(code int) Option {
+	return optionFunc(func(m *Meta) {
+		m.ExitCode = code
+	})
+}
+
+// OnShutdown registers functions that Fatal runs, in order, after writing the
+// entry and syncing the logger's outputs, but before exiting. They're the
+// place to flush other sinks, tracing buffers, or metrics. Each call adds to
+// the hooks already registered.
+func OnShutdown(hooks ...func()) Option {
+	return optionFunc(func(m *Meta) {
+		m.ShutdownHooks = append(m.ShutdownHooks, hooks...)
+	})
+}
+
+// ShutdownTimeout limits the total time that shutdown hooks may run before
+// Fatal exits anyway. The default is five seconds; non-positive values wait
+// indefinitely.
+func ShutdownTimeout(d time.Duration) Option {
+	return optionFunc(func(m *Meta) {
+		m.ShutdownTimeout = d
+	})
+}
+
+// PanicValueFunc sets a function that builds the value that Panic (and, in
+// development mode, DPanic) panics with, in place of a *PanicError.
+func PanicValueFunc(f func(lvl Level, msg string, fields []Field) interface{}) Option {
+	return optionFunc(func(m *Meta) {
+		m.PanicValueFunc = f
+	})
+}
+
 // Development puts the logger in development mode, which alters the behavior
 // of the DPanic method.
 func Development() Option {
@@ -0,0 +1,82 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// PanicError is the value that Panic (and, in development mode, DPanic)
+// panics with unless a PanicValueFunc option says otherwise. It carries the
+// structured entry that was logged, so code that recovers the panic can log
+// it again without losing context. Its Error method returns the bare message,
+// which keeps it compatible with code that expects the message string.
+type PanicError struct {
+	Level   Level
+	Message string
+	Fields  []Field
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return e.Message
+}
+
+// MarshalLog implements LogMarshaler, adding the level, message, and fields
+// (nested under "fields") to the KeyValue.
+func (e *PanicError) MarshalLog(kv KeyValue) error {
+	kv.AddString("level", e.Level.String())
+	kv.AddString("msg", e.Message)
+	return kv.AddMarshaler("fields", multiFields(e.Fields))
+}
+
+// PanicValue returns the value to panic with after logging msg and fields at
+// the given level. It calls the function set by the PanicValueFunc option if
+// there is one, and otherwise returns a *PanicError.
+func (m Meta) PanicValue(lvl Level, msg string, fields []Field) interface{} {
+	if m.PanicValueFunc != nil {
+		return m.PanicValueFunc(lvl, msg, fields)
+	}
+	return newPanicError(lvl, msg, fields)
+}
+
+func newPanicError(lvl Level, msg string, fields []Field) *PanicError {
+	// Copy the fields, since callers may reuse the variadic slice.
+	fs := make([]Field, len(fields))
+	copy(fs, fields)
+	return &PanicError{Level: lvl, Message: msg, Fields: fs}
+}
+
+// panicker is implemented by loggers that embed a Meta, and by loggers that
+// wrap or fan out to others (like Tee, Sample, and zwrap.Filter) so that
+// Panic reaches the Metas they wrap.
+type panicker interface {
+	PanicValue(Level, string, []Field) interface{}
+}
+
+// panicValueVia returns the panic value on behalf of a logger that fans out
+// to others, using the first one that implements panicker so that the
+// PanicValueFunc option of the Meta it embeds or wraps applies. If none of
+// them do, it returns a *PanicError.
+func panicValueVia(logs []Logger, lvl Level, msg string, fields []Field) interface{} {
+	for _, log := range logs {
+		if p, ok := log.(panicker); ok {
+			return p.PanicValue(lvl, msg, fields)
+		}
+	}
+	return newPanicError(lvl, msg, fields)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recoverPanic(f func()) (recovered interface{}) {
+	defer func() { recovered = recover() }()
+	f()
+	return nil
+}
+
+func TestPanicError(t *testing.T) {
+	pe := &PanicError{Level: PanicLevel, Message: "foo", Fields: []Field{Int("n", 1), String("s", "bar")}}
+	assert.Equal(t, "foo", pe.Error(), "Expected Error to return the message.")
+
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		logger.Info("recovered", Marshaler("panic", pe))
+		assert.Equal(t,
+			`{"level":"info","msg":"recovered","panic":{"level":"panic","msg":"foo","fields":{"n":1,"s":"bar"}}}`,
+			buf.Stripped(),
+			"Unexpected output from marshaling a PanicError.",
+		)
+	})
+}
+
+func TestLoggerPanicsWithPanicError(t *testing.T) {
+	withJSONLogger(t, opts(Development()), func(logger Logger, buf *testBuffer) {
+		fields := []Field{Int("n", 1)}
+		tests := []struct {
+			level Level
+			f     func()
+		}{
+			{PanicLevel, func() { logger.Panic("foo", fields...) }},
+			{PanicLevel, func() { logger.Check(PanicLevel, "foo").Write(fields...) }},
+			{DPanicLevel, func() { logger.DPanic("foo", fields...) }},
+			{DPanicLevel, func() { logger.Check(DPanicLevel, "foo").Write(fields...) }},
+		}
+		for _, tt := range tests {
+			r := recoverPanic(tt.f)
+			require.IsType(t, &PanicError{}, r, "Unexpected panic value at %v.", tt.level)
+			assert.Equal(t, &PanicError{Level: tt.level, Message: "foo", Fields: fields}, r, "Unexpected PanicError at %v.", tt.level)
+		}
+	})
+}
+
+func TestPanicErrorCopiesFields(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		fields := []Field{Int("n", 1)}
+		r := recoverPanic(func() { logger.Panic("foo", fields...) })
+		fields[0] = Int("n", 2)
+		require.IsType(t, &PanicError{}, r, "Unexpected panic value.")
+		assert.Equal(t, []Field{Int("n", 1)}, r.(*PanicError).Fields, "Expected PanicError to own its fields.")
+	})
+}
+
+func TestCompositeLoggersPanicValue(t *testing.T) {
+	custom := func(lvl Level, msg string, fields []Field) interface{} {
+		return msg + "!"
+	}
+	withJSONLogger(t, opts(PanicValueFunc(custom)), func(logger Logger, buf *testBuffer) {
+		sampled := Sample(logger, time.Minute, 10, 10)
+		tests := []struct {
+			desc string
+			log  Logger
+			want interface{}
+		}{
+			{"tee", Tee(logger, logger), "foo!"},
+			{"nested tee", Tee(Tee(logger, logger), Tee(logger, logger)), "foo!"},
+			{"tee of samplers", Tee(sampled, sampled), "foo!"},
+			{"tee of routers", Tee(Router(nil, Route{Logger: logger}), logger), "foo!"},
+			{"tee with a hidden Meta first", Tee(hiddenMeta{logger}, logger), "foo!"},
+			{"tee without Meta", Tee(hiddenMeta{logger}, hiddenMeta{logger}), &PanicError{Level: PanicLevel, Message: "foo", Fields: []Field{}}},
+			{"filter", Filter(LeveledLogger{PanicLevel, logger}), "foo!"},
+			{"filter of tee", Filter(LeveledLogger{PanicLevel, Tee(logger, logger)}), "foo!"},
+			{"filter without Meta", Filter(LeveledLogger{PanicLevel, hiddenMeta{logger}}), &PanicError{Level: PanicLevel, Message: "foo", Fields: []Field{}}},
+		}
+		for _, tt := range tests {
+			assert.Equal(t, tt.want, recoverPanic(func() { tt.log.Panic("foo") }), "Unexpected panic value from %s logger.", tt.desc)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "fmt"
+
+// Skip recovered and RecoverAndLog (or RecoverAndRepanic) when taking the
+// stack of a recovered panic.
+const _recoverSkip = 2
+
+// RecoverAndLog recovers from a panic, if there is one, and logs it at
+// ErrorLevel with the given message and fields. The recovered value is added
+// as an error field, along with the stack of the panicking goroutine under the
+// "stacktrace" key (with zap's and the runtime's frames left out). The logger
+// is synced before RecoverAndLog returns; if that fails and the logger reports
+// internal errors (as loggers embedding a Meta do), the error is reported with
+// the cause "sync". It must be deferred directly:
+//
+//   func worker(logger zap.Logger) {
+//     defer zap.RecoverAndLog(logger, "worker crashed", zap.String("queue", "jobs"))
+//     ...
+//   }
+func RecoverAndLog(log Logger, msg string, fields ...Field) {
+	if r := recover(); r != nil {
+		recovered(log, r, msg, fields)
+	}
+}
+
+// RecoverAndRepanic is like RecoverAndLog, but after logging the panic and
+// syncing the logger, it panics again with the recovered value. Use it to
+// record crashes that should still take the process down.
+func RecoverAndRepanic(log Logger, msg string, fields ...Field) {
+	if r := recover(); r != nil {
+		recovered(log, r, msg, fields)
+		panic(r)
+	}
+}
+
+// Go runs f in a new goroutine. If f panics, the panic is logged with the
+// given message and fields as with RecoverAndLog, and the goroutine exits
+// without taking down the process.
+func Go(log Logger, f func(), msg string, fields ...Field) {
+	go func() {
+		defer RecoverAndLog(log, msg, fields...)
+		f()
+	}()
+}
+
+// internalErrorReporter is implemented by loggers that embed a Meta.
+type internalErrorReporter interface {
+	InternalError(cause string, err error)
+}
+
+func recovered(log Logger, r interface{}, msg string, fields []Field) {
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("%v", r)
+	}
+	fs := make([]Field, 0, len(fields)+2)
+	fs = append(fs, fields...)
+	fs = append(fs, Error(err), String("stacktrace", takePanicStacktrace(_recoverSkip)))
+	log.Log(ErrorLevel, msg, fs...)
+	if err := log.Sync(); err != nil {
+		if r, ok := log.(internalErrorReporter); ok {
+			r.InternalError(syncCause, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uber-go/zap/spywrite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncNotifier is a testBuffer that reports calls to Sync, dropping reports
+// that nobody has received yet.
+type syncNotifier struct {
+	testBuffer
+	synced chan struct{}
+}
+
+func (s *syncNotifier) Sync() error {
+	select {
+	case s.synced <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func withRecoveryLogger(t testing.TB, f func(Logger, *syncNotifier)) {
+	sink := &syncNotifier{synced: make(chan struct{}, 1)}
+	f(New(newJSONEncoder(NoTime()), Output(sink)), sink)
+}
+
+func decodeRecovered(t testing.TB, sink *syncNotifier) map[string]interface{} {
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(sink.Stripped()), &entry), "Expected a single JSON entry.")
+	return entry
+}
+
+func panicky(v interface{}) {
+	panic(v)
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		err   string
+	}{
+		{"boom", "boom"},
+		{errors.New("failed"), "failed"},
+		{42, "42"},
+	}
+
+	for _, tt := range tests {
+		withRecoveryLogger(t, func(logger Logger, sink *syncNotifier) {
+			assert.NotPanics(t, func() {
+				defer RecoverAndLog(logger, "worker crashed", String("queue", "jobs"))
+				panicky(tt.value)
+			}, "Expected RecoverAndLog to recover from the panic.")
+
+			select {
+			case <-sink.synced:
+			default:
+				t.Error("Expected RecoverAndLog to sync the logger.")
+			}
+
+			entry := decodeRecovered(t, sink)
+			assert.Equal(t, "error", entry["level"], "Unexpected level.")
+			assert.Equal(t, "worker crashed", entry["msg"], "Unexpected message.")
+			assert.Equal(t, "jobs", entry["queue"], "Expected the passed fields.")
+			assert.Equal(t, tt.err, entry["error"], "Unexpected error field.")
+
+			stack, _ := entry["stacktrace"].(string)
+			assert.True(t, strings.HasPrefix(stack, "github.com/uber-go/zap.panicky\n"), "Expected the stack to start at the panicking function, got:\n%s", stack)
+			assert.NotContains(t, stack, "runtime.", "Expected runtime frames to be trimmed.")
+			assert.NotContains(t, stack, "zap.RecoverAndLog", "Expected zap frames to be trimmed.")
+		})
+	}
+}
+
+func TestRecoverAndLogNoPanic(t *testing.T) {
+	withRecoveryLogger(t, func(logger Logger, sink *syncNotifier) {
+		func() {
+			defer RecoverAndLog(logger, "worker crashed")
+		}()
+		assert.Equal(t, "", sink.String(), "Expected no output without a panic.")
+		assert.Equal(t, 0, len(sink.synced), "Expected no sync without a panic.")
+	})
+}
+
+func TestRecoverAndRepanic(t *testing.T) {
+	withRecoveryLogger(t, func(logger Logger, sink *syncNotifier) {
+		r := recoverPanic(func() {
+			defer RecoverAndRepanic(logger, "worker crashed")
+			panicky("boom")
+		})
+		assert.Equal(t, "boom", r, "Expected RecoverAndRepanic to panic again with the recovered value.")
+		assert.Equal(t, 1, len(sink.synced), "Expected the logger to be synced before re-panicking.")
+		assert.Equal(t, "boom", decodeRecovered(t, sink)["error"], "Unexpected error field.")
+	})
+}
+
+func TestRecoverAndLogPanicError(t *testing.T) {
+	withRecoveryLogger(t, func(logger Logger, sink *syncNotifier) {
+		func() {
+			defer RecoverAndLog(logger, "worker crashed")
+			logger.Panic("fell over", Int("n", 1))
+		}()
+		lines := sink.Lines()
+		require.Equal(t, 2, len(lines), "Expected the panic and its recovery to be logged.")
+		assert.Contains(t, lines[1], `"error":"fell over"`, "Expected the PanicError's message as the error.")
+		assert.NotContains(t, lines[1], "zap.(*logger).Panic", "Expected zap frames to be trimmed.")
+	})
+}
+
+func TestRecoverAndLogSyncError(t *testing.T) {
+	var reports []ErrorReport
+	out := &spywrite.WriteSyncer{Writer: &testBuffer{}}
+	out.SetError(errors.New("sync failed"))
+	logger := New(newJSONEncoder(NoTime()), Output(out), OnInternalError(func(r ErrorReport) {
+		reports = append(reports, r)
+	}))
+	func() {
+		defer RecoverAndLog(logger, "worker crashed")
+		panicky("boom")
+	}()
+	require.Equal(t, 1, len(reports), "Expected the sync error to be reported.")
+	assert.Equal(t, "sync", reports[0].Cause, "Unexpected cause.")
+	assert.Equal(t, "sync failed", reports[0].Err.Error(), "Unexpected error.")
+}
+
+func TestGo(t *testing.T) {
+	withRecoveryLogger(t, func(logger Logger, sink *syncNotifier) {
+		Go(logger, func() { panicky("boom") }, "worker crashed", String("queue", "jobs"))
+		select {
+		case <-sink.synced:
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for the goroutine's panic to be logged.")
+		}
+		entry := decodeRecovered(t, sink)
+		assert.Equal(t, "worker crashed", entry["msg"], "Unexpected message.")
+		assert.Equal(t, "jobs", entry["queue"], "Expected the passed fields.")
+		assert.Equal(t, "boom", entry["error"], "Unexpected error field.")
+		stack, _ := entry["stacktrace"].(string)
+		assert.True(t, strings.HasPrefix(stack, "github.com/uber-go/zap.panicky\n"), "Expected the stack to start at the panicking function, got:\n%s", stack)
+		assert.NotContains(t, stack, "zap.Go", "Expected zap frames to be trimmed.")
+	})
+}
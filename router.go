@@ -0,0 +1,388 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+
+	"github.com/uber-go/zap/internal/names"
+)
+
+// A Route sends the entries that satisfy all of its non-empty conditions to a
+// Logger.
+type Route struct {
+	// Name matches the router's logger name, as built up by calls to Named. A
+	// name matches its descendants too (e.g., "db" matches "db.pool"), and
+	// patterns with glob metacharacters are matched using path.Match.
+	Name string
+	// Field requires the entry to carry a field with this key, either at the
+	// call site or in context added with With. If Value is also set, the
+	// field's value, formatted with fmt.Sprint, must equal it.
+	Field string
+	Value string
+	// Logger receives the matching entries.
+	Logger Logger
+}
+
+// Router creates a Logger that dispatches each entry to the loggers of all the
+// routes it matches, or to the fallback logger if it matches none. The
+// fallback may be nil, in which case unmatched entries are discarded. For
+// example, to send authentication events to an audit log and everything else
+// to the main log:
+//
+//   logger := zap.Router(mainLogger, zap.Route{
+//     Field:  "event_type",
+//     Value:  "auth",
+//     Logger: auditLogger,
+//   })
+//
+// Routes that depend only on the logger's name and context are resolved by
+// Check, which chains the CheckedMessages of the matching loggers. Routes that
+// depend on call-site fields are resolved when the CheckedMessage is written,
+// but their loggers are still checked up front, so Check returns nil if none of
+// the possible targets is enabled.
+// As with Tee, Panic and Fatal only terminate the process after every matching
+// logger has received the message, and DPanic panics if any of them panicked.
+func Router(fallback Logger, routes ...Route) Logger {
+	return &router{
+		routes:   append([]Route(nil), routes...),
+		fallback: fallback,
+	}
+}
+
+type router struct {
+	routes   []Route
+	fallback Logger
+	name     string
+	// context holds the values of context fields that routes depend on.
+	context map[string]string
+}
+
+func (r *router) clone() *router {
+	return &router{
+		routes:   make([]Route, len(r.routes)),
+		fallback: r.fallback,
+		name:     r.name,
+		context:  r.context,
+	}
+}
+
+func (r *router) With(fields ...Field) Logger {
+	clone := r.clone()
+	for i, route := range r.routes {
+		clone.routes[i] = route
+		clone.routes[i].Logger = route.Logger.With(fields...)
+	}
+	if r.fallback != nil {
+		clone.fallback = r.fallback.With(fields...)
+	}
+	copied := false
+	for _, f := range fields {
+		if !r.routed(f.key) {
+			continue
+		}
+		if !copied {
+			// Copy on the first write, since parents share their context.
+			ctx := make(map[string]string, len(r.context)+1)
+			for k, v := range r.context {
+				ctx[k] = v
+			}
+			clone.context, copied = ctx, true
+		}
+		clone.context[f.key] = fieldValue(f)
+	}
+	return clone
+}
+
+func (r *router) Named(name string) Logger {
+	if name == "" {
+		return r
+	}
+	clone := r.clone()
+	for i, route := range r.routes {
+		clone.routes[i] = route
+		clone.routes[i].Logger = route.Logger.Named(name)
+	}
+	if r.fallback != nil {
+		clone.fallback = r.fallback.Named(name)
+	}
+	if r.name == "" {
+		clone.name = name
+	} else {
+		clone.name = r.name + "." + name
+	}
+	return clone
+}
+
+// routed reports whether any route depends on a field with the given key.
+func (r *router) routed(key string) bool {
+	for _, route := range r.routes {
+		if route.Field == key {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve reports whether a route matches based on the logger's name and
+// context alone. If it can't tell without the call-site fields, pending is
+// true.
+func (r *router) resolve(route *Route) (matched, pending bool) {
+	if route.Name != "" && !names.Match(route.Name, r.name) {
+		return false, false
+	}
+	if route.Field == "" {
+		return true, false
+	}
+	if v, ok := r.context[route.Field]; ok && (route.Value == "" || v == route.Value) {
+		return true, false
+	}
+	return false, true
+}
+
+// targets returns the loggers that should receive an entry with the given
+// call-site fields.
+func (r *router) targets(fields []Field) []Logger {
+	var logs []Logger
+	for i := range r.routes {
+		route := &r.routes[i]
+		matched, pending := r.resolve(route)
+		if matched || (pending && hasFieldValue(fields, route.Field, route.Value)) {
+			logs = append(logs, route.Logger)
+		}
+	}
+	if len(logs) == 0 && r.fallback != nil {
+		logs = append(logs, r.fallback)
+	}
+	return logs
+}
+
+func (r *router) Log(lvl Level, msg string, fields ...Field) {
+	for _, log := range r.targets(fields) {
+		log.Log(lvl, msg, fields...)
+	}
+}
+
+func (r *router) Debug(msg string, fields ...Field) {
+	r.write(DebugLevel, msg, fields)
+}
+
+func (r *router) Info(msg string, fields ...Field) {
+	r.write(InfoLevel, msg, fields)
+}
+
+func (r *router) Warn(msg string, fields ...Field) {
+	r.write(WarnLevel, msg, fields)
+}
+
+func (r *router) Error(msg string, fields ...Field) {
+	r.write(ErrorLevel, msg, fields)
+}
+
+func (r *router) DPanic(msg string, fields ...Field) {
+	r.write(DPanicLevel, msg, fields)
+}
+
+func (r *router) Panic(msg string, fields ...Field) {
+	r.Log(PanicLevel, msg, fields...)
+	panic(panicValueVia(r.targets(fields), PanicLevel, msg, fields))
+}
+
+func (r *router) Fatal(msg string, fields ...Field) {
+	r.Log(FatalLevel, msg, fields...)
+	exitVia(r.targets(fields))
+}
+
+// all returns every route's logger, followed by the fallback.
+func (r *router) all() []Logger {
+	logs := make([]Logger, 0, len(r.routes)+1)
+	for _, route := range r.routes {
+		logs = append(logs, route.Logger)
+	}
+	if r.fallback != nil {
+		logs = append(logs, r.fallback)
+	}
+	return logs
+}
+
+// PanicValue builds the panic value using the entry's targets, as Panic does,
+// for use when the router is part of a Tee.
+func (r *router) PanicValue(lvl Level, msg string, fields []Field) interface{} {
+	return panicValueVia(r.targets(fields), lvl, msg, fields)
+}
+
+// Shutdown shuts down every route's logger and the fallback, for use when the
+// router is part of a Tee. Since it doesn't know which entry was logged, it
+// can't narrow them down to the entry's targets.
+func (r *router) Shutdown() (func(int), int) {
+	return shutdownAll(r.all())
+}
+
+// write chains the targets' CheckedMessages, so that each target applies its
+// own level and development-mode semantics.
+func (r *router) write(lvl Level, msg string, fields []Field) {
+	var cm *CheckedMessage
+	for _, log := range r.targets(fields) {
+		cm = cm.Chain(log.Check(lvl, msg))
+	}
+	cm.Write(fields...)
+}
+
+// Sync syncs every route's logger and the fallback, even if some of them fail,
+// and returns their errors combined.
+func (r *router) Sync() error {
+	var errs multiError
+	for _, route := range r.routes {
+		if err := route.Logger.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if r.fallback != nil {
+		if err := r.fallback.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.asError()
+}
+
+func (r *router) Check(lvl Level, msg string) *CheckedMessage {
+	switch lvl {
+	case FatalLevel, PanicLevel:
+		// As in Tee, make sure that the process terminates only after all
+		// targets have received the message.
+		return NewCheckedMessage(r, lvl, msg)
+	}
+	var (
+		cm      *CheckedMessage
+		matched bool
+		pending []*CheckedMessage
+		enabled bool
+	)
+	for i := range r.routes {
+		route := &r.routes[i]
+		ok, p := r.resolve(route)
+		if ok {
+			matched = true
+			cm = cm.Chain(route.Logger.Check(lvl, msg))
+		}
+		if p {
+			if pending == nil {
+				pending = make([]*CheckedMessage, len(r.routes))
+			}
+			pending[i] = route.Logger.Check(lvl, msg)
+			enabled = enabled || pending[i].OK()
+		}
+	}
+	var fallback *CheckedMessage
+	if !matched && r.fallback != nil {
+		fallback = r.fallback.Check(lvl, msg)
+	}
+	if pending == nil {
+		return cm.Chain(fallback)
+	}
+	if !enabled && !fallback.OK() {
+		// No pending route or fallback can log at this level.
+		return cm
+	}
+	pr := &pendingRouter{router: r, pending: pending, fallback: fallback}
+	return cm.Chain(NewCheckedMessage(pr, lvl, msg))
+}
+
+// pendingRouter finishes routing a checked entry once its call-site fields are
+// known. Routes that were resolved by Check have already been chained, so it
+// only handles the pending routes and the fallback, whose CheckedMessages were
+// taken by Check; the ones that don't apply to the entry are discarded.
+type pendingRouter struct {
+	*router
+
+	pending  []*CheckedMessage // by route index, nil if not pending
+	fallback *CheckedMessage   // nil if a route was matched by Check
+}
+
+func (pr *pendingRouter) write(fields []Field) {
+	var cm *CheckedMessage
+	for i, pcm := range pr.pending {
+		route := &pr.routes[i]
+		if pcm.OK() && hasFieldValue(fields, route.Field, route.Value) {
+			cm = cm.Chain(pcm)
+		} else {
+			pcm.release()
+		}
+	}
+	// A pending route whose logger is disabled still counts as a match, so
+	// the entry doesn't fall back.
+	if pr.matchedPending(fields) {
+		pr.fallback.release()
+	} else {
+		cm = cm.Chain(pr.fallback)
+	}
+	cm.Write(fields...)
+}
+
+func (pr *pendingRouter) matchedPending(fields []Field) bool {
+	for i := range pr.pending {
+		route := &pr.routes[i]
+		if _, pending := pr.resolve(route); pending && hasFieldValue(fields, route.Field, route.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (pr *pendingRouter) Log(lvl Level, msg string, fields ...Field) {
+	pr.write(fields)
+}
+
+func (pr *pendingRouter) Debug(msg string, fields ...Field) {
+	pr.write(fields)
+}
+
+func (pr *pendingRouter) Info(msg string, fields ...Field) {
+	pr.write(fields)
+}
+
+func (pr *pendingRouter) Warn(msg string, fields ...Field) {
+	pr.write(fields)
+}
+
+func (pr *pendingRouter) Error(msg string, fields ...Field) {
+	pr.write(fields)
+}
+
+func (pr *pendingRouter) DPanic(msg string, fields ...Field) {
+	pr.write(fields)
+}
+
+// hasFieldValue reports whether any of the fields has the given key and, if
+// value isn't empty, a matching value.
+func hasFieldValue(fields []Field, key, value string) bool {
+	for _, f := range fields {
+		if f.key == key && (value == "" || fieldValue(f) == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldValue formats a field's value with fmt.Sprint.
+func fieldValue(f Field) string {
+	return fmt.Sprint(f.Value())
+}
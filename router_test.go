@@ -0,0 +1,173 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+	"github.com/uber-go/zap/spywrite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func msgs(sink *spy.Sink) []string {
+	var out []string
+	for _, l := range sink.Logs() {
+		out = append(out, l.Msg)
+	}
+	return out
+}
+
+func TestRouterFields(t *testing.T) {
+	main, mainSink := spy.New(zap.DebugLevel)
+	audit, auditSink := spy.New(zap.DebugLevel)
+	log := zap.Router(main, zap.Route{Field: "event_type", Value: "auth", Logger: audit})
+
+	log.Info("login", zap.String("event_type", "auth"))
+	log.Info("query", zap.String("event_type", "db"))
+	log.Info("plain")
+	log.With(zap.String("event_type", "auth")).Warn("logout")
+	log.With(zap.String("event_type", "db")).Warn("commit", zap.String("event_type", "auth"))
+
+	assert.Equal(t, []string{"login", "logout", "commit"}, msgs(auditSink), "Unexpected audit logs.")
+	assert.Equal(t, []string{"query", "plain"}, msgs(mainSink), "Unexpected fallback logs.")
+}
+
+func TestRouterNames(t *testing.T) {
+	main, mainSink := spy.New(zap.DebugLevel)
+	db, dbSink := spy.New(zap.DebugLevel)
+	all, allSink := spy.New(zap.DebugLevel)
+	log := zap.Router(
+		main,
+		zap.Route{Name: "db", Logger: db},
+		zap.Route{Name: "*b*", Logger: all},
+	)
+
+	log.Info("unnamed")
+	log.Named("db").Info("db")
+	log.Named("db").Named("pool").Info("db.pool")
+	log.Named("http").Info("http")
+
+	assert.Equal(t, []string{"unnamed", "http"}, msgs(mainSink), "Unexpected fallback logs.")
+	assert.Equal(t, []string{"db", "db.pool"}, msgs(dbSink), "Unexpected db logs.")
+	assert.Equal(t, []string{"db", "db.pool"}, msgs(allSink), "Unexpected logs for glob route.")
+	assert.Equal(t, "db.pool", dbSink.Logs()[1].Name, "Expected route loggers to be named too.")
+}
+
+func TestRouterNilFallback(t *testing.T) {
+	audit, auditSink := spy.New(zap.DebugLevel)
+	log := zap.Router(nil, zap.Route{Field: "audit", Logger: audit})
+
+	log.Info("dropped")
+	log.Info("kept", zap.Bool("audit", true))
+	log.Check(zap.InfoLevel, "checked and dropped").Write()
+	named := zap.Router(nil, zap.Route{Name: "db", Logger: audit})
+	assert.Nil(t, named.Check(zap.InfoLevel, "unrouted"), "Expected nil CheckedMessage for unrouted entries.")
+	assert.Equal(t, []string{"kept"}, msgs(auditSink), "Unexpected audit logs.")
+	assert.NoError(t, log.Named("foo").With(zap.Int("n", 1)).Sync(), "Unexpected error syncing.")
+}
+
+func TestRouterCheck(t *testing.T) {
+	main, mainSink := spy.New(zap.DebugLevel)
+	audit, auditSink := spy.New(zap.WarnLevel)
+	db, dbSink := spy.New(zap.DebugLevel)
+	log := zap.Router(
+		main,
+		zap.Route{Field: "event_type", Value: "auth", Logger: audit},
+		zap.Route{Name: "db", Logger: db},
+	)
+
+	// Statically resolved routes are chained directly.
+	cm := log.Named("db").Check(zap.InfoLevel, "static")
+	require.True(t, cm.OK(), "Expected a CheckedMessage.")
+	// The audit logger is disabled at InfoLevel, so it doesn't get a copy.
+	cm.Write(zap.String("event_type", "auth"))
+
+	// Field-based routes are resolved on Write.
+	log.Check(zap.InfoLevel, "pending").Write(zap.String("event_type", "auth"))
+	log.Check(zap.WarnLevel, "pending").Write(zap.String("event_type", "auth"))
+	log.Check(zap.InfoLevel, "fallback").Write()
+
+	// Context can resolve field routes during Check.
+	withAuth := log.With(zap.String("event_type", "auth"))
+	assert.Nil(t, withAuth.Check(zap.InfoLevel, "disabled").Chain(), "Expected disabled audit level to be respected.")
+	withAuth.Check(zap.ErrorLevel, "context").Write()
+
+	assert.Equal(t, []string{"static"}, msgs(dbSink), "Unexpected db logs.")
+	assert.Equal(t, []string{"pending", "context"}, msgs(auditSink), "Unexpected audit logs.")
+	assert.Equal(t, []string{"fallback"}, msgs(mainSink), "Unexpected fallback logs.")
+}
+
+func TestRouterCheckDisabled(t *testing.T) {
+	main, mainSink := spy.New(zap.WarnLevel)
+	audit, auditSink := spy.New(zap.ErrorLevel)
+	log := zap.Router(main, zap.Route{Field: "event_type", Value: "auth", Logger: audit})
+
+	assert.Nil(t, log.Check(zap.InfoLevel, "disabled"), "Expected nil CheckedMessage when no target is enabled.")
+	noFallback := zap.Router(nil, zap.Route{Field: "event_type", Logger: audit})
+	assert.Nil(t, noFallback.Check(zap.WarnLevel, "disabled"), "Expected nil CheckedMessage when no route is enabled.")
+
+	// Only the fallback is enabled at WarnLevel, so matching entries are
+	// dropped rather than falling back.
+	log.Check(zap.WarnLevel, "matched").Write(zap.String("event_type", "auth"))
+	log.Check(zap.WarnLevel, "unmatched").Write()
+	log.Check(zap.ErrorLevel, "both").Write(zap.String("event_type", "auth"))
+
+	assert.Equal(t, []string{"both"}, msgs(auditSink), "Unexpected audit logs.")
+	assert.Equal(t, []string{"unmatched"}, msgs(mainSink), "Unexpected fallback logs.")
+}
+
+func TestRouterPanic(t *testing.T) {
+	main, mainSink := spy.New(zap.DebugLevel)
+	audit, auditSink := spy.New(zap.DebugLevel)
+	log := zap.Router(main, zap.Route{Field: "audit", Logger: audit})
+
+	assert.Panics(t, func() { log.Panic("panic", zap.Bool("audit", true)) }, "Expected Panic to panic.")
+	assert.Panics(t, func() { log.Check(zap.PanicLevel, "checked").Write() }, "Expected checked Panic to panic.")
+	assert.Equal(t, []string{"panic"}, msgs(auditSink), "Unexpected audit logs.")
+	assert.Equal(t, []string{"checked"}, msgs(mainSink), "Unexpected fallback logs.")
+}
+
+func TestRouterDPanic(t *testing.T) {
+	dev, devSink := spy.New(zap.DebugLevel, zap.Development())
+	prod, prodSink := spy.New(zap.DebugLevel)
+	log := zap.Router(nil, zap.Route{Logger: dev}, zap.Route{Logger: prod})
+
+	assert.Panics(t, func() { log.DPanic("dpanic") }, "Expected DPanic to panic in development.")
+	assert.Panics(t, func() { log.Check(zap.DPanicLevel, "checked").Write() }, "Expected checked DPanic to panic in development.")
+	assert.Equal(t, []string{"dpanic", "checked"}, msgs(devSink), "Unexpected development logs.")
+	assert.Equal(t, []string{"dpanic", "checked"}, msgs(prodSink), "Expected every route to receive DPanic messages.")
+}
+
+func TestRouterSync(t *testing.T) {
+	failing := &spywrite.WriteSyncer{Writer: ioutil.Discard}
+	failing.SetError(errors.New("failed"))
+	ok, sink := spy.New()
+	log := zap.Router(zap.New(zap.NewJSONEncoder(), zap.Output(failing)), zap.Route{Logger: ok})
+
+	assert.EqualError(t, log.Sync(), "failed ", "Expected errors from the fallback to be returned.")
+	assert.Equal(t, 1, sink.Syncs(), "Expected route loggers to be synced.")
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"sort"
+)
+
+// A fieldSpan records where a single serialized field lives in an encoder's
+// buffer. Encoders that sort their output by key track a span for each field,
+// since context added by With is already serialized by the time the entry is
+// written.
+type fieldSpan struct {
+	start    int // first byte of the field, after any separator
+	keyStart int // first byte of the (possibly escaped) key
+	keyEnd   int // one past the last byte of the key
+	end      int // one past the last byte of the value; set by measureSpans
+}
+
+type spansByKey struct {
+	buf   []byte
+	spans []fieldSpan
+}
+
+func (s spansByKey) Len() int      { return len(s.spans) }
+func (s spansByKey) Swap(i, j int) { s.spans[i], s.spans[j] = s.spans[j], s.spans[i] }
+func (s spansByKey) Less(i, j int) bool {
+	a, b := s.spans[i], s.spans[j]
+	return bytes.Compare(s.buf[a.keyStart:a.keyEnd], s.buf[b.keyStart:b.keyEnd]) < 0
+}
+
+// measureSpans fills in the end of each span, assuming that the fields are
+// separated by a single byte and that the last field ends at end.
+func measureSpans(spans []fieldSpan, end int) {
+	for i := range spans {
+		if i+1 < len(spans) {
+			spans[i].end = spans[i+1].start - 1
+		} else {
+			spans[i].end = end
+		}
+	}
+}
+
+// sortSpans stably sorts measured spans by key, so fields with duplicate keys
+// keep their relative order.
+func sortSpans(buf []byte, spans []fieldSpan) {
+	sort.Stable(spansByKey{buf, spans})
+}
+
+// appendSpans appends the fields described by a sorted slice of spans to dst,
+// separating them with sep.
+func appendSpans(dst, buf []byte, spans []fieldSpan, sep byte) []byte {
+	for i, s := range spans {
+		if i > 0 {
+			dst = append(dst, sep)
+		}
+		dst = append(dst, buf[s.start:s.end]...)
+	}
+	return dst
+}
+
+// sortTrailingFields sorts, in place, the fields described by spans. The
+// fields must be the last thing in the buffer, so it's primarily useful for
+// nested objects that have just been completely serialized.
+func sortTrailingFields(buf []byte, spans []fieldSpan, sep byte) {
+	if len(spans) < 2 {
+		return
+	}
+	start := spans[0].start
+	measureSpans(spans, len(buf))
+	sortSpans(buf, spans)
+
+	scratch := jsonPool.Get().(*jsonEncoder)
+	scratch.truncate()
+	scratch.bytes = appendSpans(scratch.bytes, buf, spans, sep)
+	copy(buf[start:], scratch.bytes)
+	scratch.Free()
+}
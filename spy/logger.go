@@ -22,6 +22,7 @@ package spy
 
 import (
 	"sync"
+	"time"
 
 	"github.com/uber-go/zap"
 )
@@ -29,6 +30,8 @@ import (
 // A Log is an encoding-agnostic representation of a log message.
 type Log struct {
 	Level  zap.Level
+	Time   time.Time
+	Name   string
 	Msg    string
 	Fields []zap.Field
 }
@@ -37,21 +40,34 @@ type Log struct {
 type Sink struct {
 	sync.Mutex
 
-	logs []Log
+	logs  []Log
+	syncs int
 }
 
-// WriteLog writes a log message to the LogSink.
+// WriteLog writes a log message to the LogSink. The message's Time is left
+// unset.
 func (s *Sink) WriteLog(lvl zap.Level, msg string, fields []zap.Field) {
-	s.Lock()
-	log := Log{
+	s.write(Log{
 		Msg:    msg,
 		Level:  lvl,
 		Fields: fields,
-	}
+	})
+}
+
+func (s *Sink) write(log Log) {
+	s.Lock()
 	s.logs = append(s.logs, log)
 	s.Unlock()
 }
 
+// Syncs returns the number of times loggers writing to the sink have been
+// synced.
+func (s *Sink) Syncs() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.syncs
+}
+
 // Logs returns a copy of the sink's accumulated logs.
 func (s *Sink) Logs() []Log {
 	var logs []Log
@@ -74,15 +90,25 @@ type Logger struct {
 // returns the logger and its sink.
 //
 // Options can change things like log level and initial fields, but any output
-// related options will not be honored.
+// related options will not be honored. Logs are stamped with the zero time
+// unless a clock is supplied with zap.WithClock.
 func New(options ...zap.Option) (*Logger, *Sink) {
 	s := &Sink{}
+	opts := make([]zap.Option, 0, len(options)+1)
+	opts = append(opts, zap.WithClock(zeroClock{}))
+	opts = append(opts, options...)
 	return &Logger{
-		Meta: zap.MakeMeta(zap.NewJSONEncoder(zap.NoTime()), options...),
+		Meta: zap.MakeMeta(zap.NewJSONEncoder(zap.NoTime()), opts...),
 		sink: s,
 	}, s
 }
 
+// zeroClock is always stopped at the zero time, which keeps logs comparable
+// in tests.
+type zeroClock struct{}
+
+func (zeroClock) Now() time.Time { return time.Time{} }
+
 // With creates a new Logger with additional fields added to the logging context.
 func (l *Logger) With(fields ...zap.Field) zap.Logger {
 	return &Logger{
@@ -92,6 +118,23 @@ func (l *Logger) With(fields ...zap.Field) zap.Logger {
 	}
 }
 
+// Named creates a new Logger with the given segment appended to its name.
+func (l *Logger) Named(name string) zap.Logger {
+	return &Logger{
+		Meta:    l.Meta.Named(name),
+		sink:    l.sink,
+		context: l.context,
+	}
+}
+
+// Sync records the call on the logger's Sink.
+func (l *Logger) Sync() error {
+	l.sink.Lock()
+	l.sink.syncs++
+	l.sink.Unlock()
+	return nil
+}
+
 // Check returns a CheckedMessage if logging a particular message would succeed.
 func (l *Logger) Check(lvl zap.Level, msg string) *zap.CheckedMessage {
 	return l.Meta.Check(l, lvl, msg)
@@ -138,13 +181,19 @@ func (l *Logger) Fatal(msg string, fields ...zap.Field) {
 func (l *Logger) DPanic(msg string, fields ...zap.Field) {
 	l.log(zap.DPanicLevel, msg, fields)
 	if l.Development {
-		panic(msg)
+		panic(l.Meta.PanicValue(zap.DPanicLevel, msg, fields))
 	}
 }
 
 func (l *Logger) log(lvl zap.Level, msg string, fields []zap.Field) {
 	if l.Meta.Enabled(lvl) {
-		l.sink.WriteLog(lvl, msg, l.allFields(fields))
+		l.sink.write(Log{
+			Level:  lvl,
+			Time:   l.Meta.Now(),
+			Name:   l.Name,
+			Msg:    msg,
+			Fields: l.allFields(fields),
+		})
 	}
 }
 
@@ -20,7 +20,16 @@
 
 package zap
 
-import "runtime"
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+
+	"github.com/uber-go/zap/internal/frames"
+)
+
+// _maxPanicDepth bounds the number of frames in a recovered panic's stack.
+const _maxPanicDepth = 64
 
 // takeStacktrace attempts to use the provided byte slice to take a stacktrace.
 // If the provided slice isn't large enough, takeStacktrace will allocate
@@ -40,3 +49,26 @@ func takeStacktrace(buf []byte, includeAllGoroutines bool) string {
 	}
 	return string(buf[:n])
 }
+
+// takePanicStacktrace formats the stack of a goroutine that's recovering from
+// a panic, skipping the given number of frames above its caller. Frames that
+// belong to zap (test files excepted) or to the runtime, like runtime.gopanic,
+// are left out, so the stack starts at the code that panicked.
+func takePanicStacktrace(skip int) string {
+	var buf bytes.Buffer
+	// Skip takePanicStacktrace itself.
+	for _, f := range frames.Callers(skip+1, _maxPanicDepth) {
+		if frames.InZap(f) || frames.InRuntime(f) {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(f.Function)
+		buf.WriteString("\n\t")
+		buf.WriteString(f.File)
+		buf.WriteByte(':')
+		buf.WriteString(strconv.Itoa(f.Line))
+	}
+	return buf.String()
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "fmt"
+
+const (
+	_oddNumberErrMsg    = "Ignored key without a value."
+	_nonStringKeyErrMsg = "Ignored key-value pair with non-string key."
+)
+
+// A SugaredLogger wraps a Logger with a slower but less verbose API. Any
+// Logger can be sugared with Sugar, and Desugar returns the original.
+//
+// Each level has three variants. The plain ones (Info, Warn, etc.) build the
+// message with fmt.Sprint, the "f" ones (Infof) with fmt.Sprintf, and the "w"
+// ones (Infow) take a constant message followed by loosely-typed key-value
+// pairs:
+//
+//   sugar.Infow("Failed to fetch URL.", "url", url, "attempt", 3)
+//
+// Keys must be strings. Ready-made Fields may be mixed in with the pairs, and
+// stand on their own. Pairs with non-string keys and dangling keys are dropped
+// and reported at DPanicLevel.
+//
+// When the wrapped Logger embeds a Meta (or otherwise implements
+// LevelEnabler), disabled levels below DPanicLevel are rejected before any
+// formatting or field conversion takes place.
+type SugaredLogger struct {
+	core    Logger
+	enabler LevelEnabler
+}
+
+// Sugar wraps a Logger to provide a more ergonomic, but slower, API.
+func Sugar(log Logger) *SugaredLogger {
+	enabler, _ := log.(LevelEnabler)
+	return &SugaredLogger{core: log, enabler: enabler}
+}
+
+// Desugar unwraps a SugaredLogger, exposing the original Logger.
+func (s *SugaredLogger) Desugar() Logger {
+	return s.core
+}
+
+// With adds a variadic number of fields to the logging context. It accepts the
+// same mix of key-value pairs and Fields as the "w" methods.
+func (s *SugaredLogger) With(args ...interface{}) *SugaredLogger {
+	return Sugar(s.core.With(s.sweetenFields(args)...))
+}
+
+// Sync flushes any buffered log entries.
+func (s *SugaredLogger) Sync() error {
+	return s.core.Sync()
+}
+
+// Debug uses fmt.Sprint to construct and log a message.
+func (s *SugaredLogger) Debug(args ...interface{}) {
+	s.log(DebugLevel, "", args, nil)
+}
+
+// Info uses fmt.Sprint to construct and log a message.
+func (s *SugaredLogger) Info(args ...interface{}) {
+	s.log(InfoLevel, "", args, nil)
+}
+
+// Warn uses fmt.Sprint to construct and log a message.
+func (s *SugaredLogger) Warn(args ...interface{}) {
+	s.log(WarnLevel, "", args, nil)
+}
+
+// Error uses fmt.Sprint to construct and log a message.
+func (s *SugaredLogger) Error(args ...interface{}) {
+	s.log(ErrorLevel, "", args, nil)
+}
+
+// DPanic uses fmt.Sprint to construct and log a message. In development, the
+// logger then panics.
+func (s *SugaredLogger) DPanic(args ...interface{}) {
+	s.log(DPanicLevel, "", args, nil)
+}
+
+// Panic uses fmt.Sprint to construct and log a message, then panics.
+func (s *SugaredLogger) Panic(args ...interface{}) {
+	s.log(PanicLevel, "", args, nil)
+}
+
+// Fatal uses fmt.Sprint to construct and log a message, then calls os.Exit.
+func (s *SugaredLogger) Fatal(args ...interface{}) {
+	s.log(FatalLevel, "", args, nil)
+}
+
+// Debugf uses fmt.Sprintf to log a templated message.
+func (s *SugaredLogger) Debugf(template string, args ...interface{}) {
+	s.log(DebugLevel, template, args, nil)
+}
+
+// Infof uses fmt.Sprintf to log a templated message.
+func (s *SugaredLogger) Infof(template string, args ...interface{}) {
+	s.log(InfoLevel, template, args, nil)
+}
+
+// Warnf uses fmt.Sprintf to log a templated message.
+func (s *SugaredLogger) Warnf(template string, args ...interface{}) {
+	s.log(WarnLevel, template, args, nil)
+}
+
+// Errorf uses fmt.Sprintf to log a templated message.
+func (s *SugaredLogger) Errorf(template string, args ...interface{}) {
+	s.log(ErrorLevel, template, args, nil)
+}
+
+// DPanicf uses fmt.Sprintf to log a templated message. In development, the
+// logger then panics.
+func (s *SugaredLogger) DPanicf(template string, args ...interface{}) {
+	s.log(DPanicLevel, template, args, nil)
+}
+
+// Panicf uses fmt.Sprintf to log a templated message, then panics.
+func (s *SugaredLogger) Panicf(template string, args ...interface{}) {
+	s.log(PanicLevel, template, args, nil)
+}
+
+// Fatalf uses fmt.Sprintf to log a templated message, then calls os.Exit.
+func (s *SugaredLogger) Fatalf(template string, args ...interface{}) {
+	s.log(FatalLevel, template, args, nil)
+}
+
+// Debugw logs a message with some additional context.
+func (s *SugaredLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	s.log(DebugLevel, msg, nil, keysAndValues)
+}
+
+// Infow logs a message with some additional context.
+func (s *SugaredLogger) Infow(msg string, keysAndValues ...interface{}) {
+	s.log(InfoLevel, msg, nil, keysAndValues)
+}
+
+// Warnw logs a message with some additional context.
+func (s *SugaredLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	s.log(WarnLevel, msg, nil, keysAndValues)
+}
+
+// Errorw logs a message with some additional context.
+func (s *SugaredLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	s.log(ErrorLevel, msg, nil, keysAndValues)
+}
+
+// DPanicw logs a message with some additional context. In development, the
+// logger then panics.
+func (s *SugaredLogger) DPanicw(msg string, keysAndValues ...interface{}) {
+	s.log(DPanicLevel, msg, nil, keysAndValues)
+}
+
+// Panicw logs a message with some additional context, then panics.
+func (s *SugaredLogger) Panicw(msg string, keysAndValues ...interface{}) {
+	s.log(PanicLevel, msg, nil, keysAndValues)
+}
+
+// Fatalw logs a message with some additional context, then calls os.Exit.
+func (s *SugaredLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	s.log(FatalLevel, msg, nil, keysAndValues)
+}
+
+func (s *SugaredLogger) log(lvl Level, template string, fmtArgs []interface{}, context []interface{}) {
+	if !s.enabled(lvl) {
+		return
+	}
+
+	msg := template
+	if len(fmtArgs) > 0 {
+		if template == "" {
+			msg = fmt.Sprint(fmtArgs...)
+		} else {
+			msg = fmt.Sprintf(template, fmtArgs...)
+		}
+	}
+	fields := s.sweetenFields(context)
+
+	switch lvl {
+	case DebugLevel:
+		s.core.Debug(msg, fields...)
+	case InfoLevel:
+		s.core.Info(msg, fields...)
+	case WarnLevel:
+		s.core.Warn(msg, fields...)
+	case ErrorLevel:
+		s.core.Error(msg, fields...)
+	case DPanicLevel:
+		s.core.DPanic(msg, fields...)
+	case PanicLevel:
+		s.core.Panic(msg, fields...)
+	case FatalLevel:
+		s.core.Fatal(msg, fields...)
+	default:
+		s.core.Log(lvl, msg, fields...)
+	}
+}
+
+func (s *SugaredLogger) enabled(lvl Level) bool {
+	switch lvl {
+	case DPanicLevel, PanicLevel, FatalLevel:
+		// Panic and Fatal should always cause a panic/exit, even if the level
+		// is disabled, and so should DPanic in development. Only the wrapped
+		// Logger knows whether it's in development, so let it decide.
+		return true
+	}
+	return s.enabler == nil || s.enabler.Enabled(lvl)
+}
+
+func (s *SugaredLogger) sweetenFields(args []interface{}) []Field {
+	if len(args) == 0 {
+		return nil
+	}
+
+	fields := make([]Field, 0, len(args))
+	for i := 0; i < len(args); {
+		if f, ok := args[i].(Field); ok {
+			fields = append(fields, f)
+			i++
+			continue
+		}
+
+		if i == len(args)-1 {
+			s.core.DPanic(_oddNumberErrMsg, Any("ignored", args[i]))
+			break
+		}
+
+		key, val := args[i], args[i+1]
+		if keyStr, ok := key.(string); ok {
+			fields = append(fields, Any(keyStr, val))
+		} else {
+			s.core.DPanic(_nonStringKeyErrMsg, Int("position", i), Any("key", key), Any("value", val))
+		}
+		i += 2
+	}
+	return fields
+}
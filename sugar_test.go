@@ -0,0 +1,169 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingStringer struct{ calls int }
+
+func (c *countingStringer) String() string {
+	c.calls++
+	return "counted"
+}
+
+func TestSugarWLevels(t *testing.T) {
+	log, sink := spy.New(zap.DebugLevel)
+	sugar := zap.Sugar(log)
+
+	err := errors.New("fail")
+	sugar.Debugw("debug", "foo", 42)
+	sugar.Infow("info", "foo", "bar", zap.String("baz", "quux"), "err", err)
+	sugar.Warnw("warn")
+	sugar.Errorw("error", zap.Int("n", 1))
+	sugar.DPanicw("dpanic", "ok", true)
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.DebugLevel, Msg: "debug", Fields: []zap.Field{zap.Int("foo", 42)}},
+		{Level: zap.InfoLevel, Msg: "info", Fields: []zap.Field{zap.String("foo", "bar"), zap.String("baz", "quux"), zap.Any("err", err)}},
+		{Level: zap.WarnLevel, Msg: "warn", Fields: []zap.Field{}},
+		{Level: zap.ErrorLevel, Msg: "error", Fields: []zap.Field{zap.Int("n", 1)}},
+		{Level: zap.DPanicLevel, Msg: "dpanic", Fields: []zap.Field{zap.Bool("ok", true)}},
+	}, sink.Logs(), "Unexpected output from sugared logger.")
+}
+
+func TestSugarFormattedLevels(t *testing.T) {
+	log, sink := spy.New(zap.DebugLevel)
+	sugar := zap.Sugar(log)
+
+	sugar.Debug("debug ", 1)
+	sugar.Info("info")
+	sugar.Warnf("warn %d", 2)
+	sugar.Errorf("error %s", "three")
+	sugar.DPanicf("no args")
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.DebugLevel, Msg: "debug 1", Fields: []zap.Field{}},
+		{Level: zap.InfoLevel, Msg: "info", Fields: []zap.Field{}},
+		{Level: zap.WarnLevel, Msg: "warn 2", Fields: []zap.Field{}},
+		{Level: zap.ErrorLevel, Msg: "error three", Fields: []zap.Field{}},
+		{Level: zap.DPanicLevel, Msg: "no args", Fields: []zap.Field{}},
+	}, sink.Logs(), "Unexpected output from sugared logger.")
+}
+
+func TestSugarWith(t *testing.T) {
+	log, sink := spy.New(zap.DebugLevel)
+	sugar := zap.Sugar(log).With("foo", "bar", zap.Int("n", 1))
+	sugar.Info("hello")
+
+	assert.Equal(t, []spy.Log{{
+		Level:  zap.InfoLevel,
+		Msg:    "hello",
+		Fields: []zap.Field{zap.String("foo", "bar"), zap.Int("n", 1)},
+	}}, sink.Logs(), "Expected context to be added by With.")
+}
+
+func TestSugarInvalidPairs(t *testing.T) {
+	log, sink := spy.New(zap.DebugLevel)
+	sugar := zap.Sugar(log)
+
+	sugar.Infow("odd", "foo", "bar", "dangling")
+	sugar.Infow("non-string key", 42, "bar", "foo", "baz")
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.DPanicLevel, Msg: "Ignored key without a value.", Fields: []zap.Field{zap.String("ignored", "dangling")}},
+		{Level: zap.InfoLevel, Msg: "odd", Fields: []zap.Field{zap.String("foo", "bar")}},
+		{
+			Level:  zap.DPanicLevel,
+			Msg:    "Ignored key-value pair with non-string key.",
+			Fields: []zap.Field{zap.Int("position", 0), zap.Int("key", 42), zap.String("value", "bar")},
+		},
+		{Level: zap.InfoLevel, Msg: "non-string key", Fields: []zap.Field{zap.String("foo", "baz")}},
+	}, sink.Logs(), "Expected invalid pairs to be dropped and reported.")
+
+	dev, _ := spy.New(zap.Development())
+	assert.Panics(t, func() { zap.Sugar(dev).Infow("odd", "dangling") }, "Expected invalid pairs to panic in development.")
+}
+
+func TestSugarDisabledLevels(t *testing.T) {
+	log, sink := spy.New(zap.ErrorLevel)
+	sugar := zap.Sugar(log)
+	s := &countingStringer{}
+
+	sugar.Debug(s)
+	sugar.Infof("%v", s)
+	sugar.Warnw("warn", "s", s, 42, "invalid")
+
+	assert.Equal(t, 0, s.calls, "Expected disabled levels to skip formatting.")
+	assert.Empty(t, sink.Logs(), "Expected disabled levels to be dropped.")
+}
+
+func TestSugarPanicAndFatal(t *testing.T) {
+	log := zap.New(zap.NewJSONEncoder(), zap.ErrorLevel, zap.Output(zap.AddSync(discard{})))
+	sugar := zap.Sugar(log)
+	assert.Panics(t, func() { sugar.Panic("foo") }, "Expected Panic to panic.")
+	assert.Panics(t, func() { sugar.Panicf("%s", "foo") }, "Expected Panicf to panic.")
+	assert.Panics(t, func() { sugar.Panicw("foo", "k", "v") }, "Expected Panicw to panic.")
+}
+
+func TestSugarDPanicInDevelopment(t *testing.T) {
+	disabled := zap.LevelEnablerFunc(func(zap.Level) bool { return false })
+	log, sink := spy.New(disabled, zap.Development())
+	sugar := zap.Sugar(log)
+	assert.Panics(t, func() { sugar.DPanic("foo") }, "Expected DPanic to panic in development.")
+	assert.Panics(t, func() { sugar.DPanicf("%s", "foo") }, "Expected DPanicf to panic in development.")
+	assert.Panics(t, func() { sugar.DPanicw("foo", "k", "v") }, "Expected DPanicw to panic in development.")
+	assert.Empty(t, sink.Logs(), "Expected disabled DPanic entries not to be logged.")
+
+	log, sink = spy.New(disabled)
+	assert.NotPanics(t, func() { zap.Sugar(log).DPanic("foo") }, "Expected DPanic not to panic in production.")
+	assert.Empty(t, sink.Logs(), "Expected disabled DPanic entries not to be logged.")
+}
+
+func TestSugarSync(t *testing.T) {
+	log, sink := spy.New()
+	assert.NoError(t, zap.Sugar(log).Sync(), "Unexpected error syncing sugared logger.")
+	assert.Equal(t, 1, sink.Syncs(), "Expected Sync to reach the wrapped logger.")
+}
+
+func TestSugarDesugar(t *testing.T) {
+	log, _ := spy.New()
+	assert.Equal(t, zap.Logger(log), zap.Sugar(log).Desugar(), "Expected Desugar to return the wrapped Logger.")
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
+
+func BenchmarkSugarDisabledLevel(b *testing.B) {
+	sugar := zap.Sugar(zap.New(zap.NewJSONEncoder(), zap.ErrorLevel, zap.Output(zap.AddSync(discard{}))))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sugar.Infow("Disabled.", "foo", 42, "bar", "baz")
+	}
+}
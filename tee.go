@@ -26,20 +26,30 @@ package zap
 // For each logging level method (.Debug, .Info, etc), the Tee calls
 // each sub-logger's level method.
 //
-// Exceptions are made for the DPanic, Panic, and Fatal methods: the returned
-// logger calls .Log(DPanicLevel, ...), .Log(PanicLevel, ...), and
-// .Log(FatalLevel, ...) respectively. Only after all sub-loggers have received
-// the message, then the Tee terminates the process (using os.Exit or panic()
-// per usual semantics).
+// Exceptions are made for the Panic and Fatal methods: the returned logger
+// calls .Log(PanicLevel, ...) and .Log(FatalLevel, ...) respectively. Only
+// after all sub-loggers have received the message, then the Tee terminates
+// the process. Panic panics with the value built by the first sub-logger
+// that embeds or wraps a Meta (see Meta.PanicValue), or a *PanicError if none
+// do. Fatal shuts down every sub-logger, running all of their shutdown hooks,
+// then exits using the first exit function set by any of them and that
+// sub-logger's exit code (see Meta.Shutdown); without one, it calls os.Exit
+// with the exit code of the first sub-logger that embeds or wraps a Meta.
+// Hooks shared by several sub-loggers (for example, a logger and its With
+// children) run once for each of them.
 //
-// NOTE: DPanic will currently never panic, since the Tee Logger does not
-// accept options (nor even have a development flag).
+// The Tee doesn't have a development flag of its own. Instead, DPanic calls
+// each sub-logger's DPanic method and, once all of them have received the
+// message, panics if any of them panicked. In other words, a Tee is in
+// development mode if any of its sub-loggers are.
 //
 // Check returns a CheckedMessage chain of any OK CheckedMessages returned by
 // all sub-loggers. The returned message is OK if any of the sub-messages are.
 // An exception is made for FatalLevel and PanicLevel, where a CheckedMessage
 // is returned against the Tee itself. This is so that tlog.Check(PanicLevel,
 // ...).Write(...) is equivalent to tlog.Panic(...) (likewise for FatalLevel).
+// Writing a chain at DPanicLevel delivers the message to every sub-logger
+// before panicking, just like DPanic.
 func Tee(logs ...Logger) Logger {
 	switch len(logs) {
 	case 0:
@@ -75,12 +85,23 @@ func (ml multiLogger) Error(msg string, fields ...Field) {
 
 func (ml multiLogger) Panic(msg string, fields ...Field) {
 	ml.log(PanicLevel, msg, fields)
-	panic(msg)
+	panic(panicValueVia(ml, PanicLevel, msg, fields))
 }
 
 func (ml multiLogger) Fatal(msg string, fields ...Field) {
 	ml.log(FatalLevel, msg, fields)
-	_exit(1)
+	exitVia(ml)
+}
+
+// PanicValue lets a Tee nested in another fan-out logger build the panic value
+// as its own Panic method would.
+func (ml multiLogger) PanicValue(lvl Level, msg string, fields []Field) interface{} {
+	return panicValueVia(ml, lvl, msg, fields)
+}
+
+// Shutdown shuts down every sub-logger, as Fatal does.
+func (ml multiLogger) Shutdown() (func(int), int) {
+	return shutdownAll(ml)
 }
 
 func (ml multiLogger) log(lvl Level, msg string, fields []Field) {
@@ -90,8 +111,18 @@ func (ml multiLogger) log(lvl Level, msg string, fields []Field) {
 }
 
 func (ml multiLogger) DPanic(msg string, fields ...Field) {
-	ml.log(DPanicLevel, msg, fields)
-	// TODO: Implement development/DPanic?
+	var (
+		recovered interface{}
+		panicked  bool
+	)
+	for _, log := range ml {
+		if r, ok := catchDPanic(log, msg, fields); ok && !panicked {
+			recovered, panicked = r, true
+		}
+	}
+	if panicked {
+		panic(recovered)
+	}
 }
 
 func (ml multiLogger) With(fields ...Field) Logger {
@@ -102,6 +133,26 @@ func (ml multiLogger) With(fields ...Field) Logger {
 	return clone
 }
 
+func (ml multiLogger) Named(name string) Logger {
+	clone := make(multiLogger, len(ml))
+	for i := range ml {
+		clone[i] = ml[i].Named(name)
+	}
+	return clone
+}
+
+// Sync syncs every sub-logger, even if some of them fail, and returns their
+// errors combined.
+func (ml multiLogger) Sync() error {
+	var errs multiError
+	for _, log := range ml {
+		if err := log.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.asError()
+}
+
 func (ml multiLogger) Check(lvl Level, msg string) *CheckedMessage {
 	switch lvl {
 	case FatalLevel, PanicLevel:
@@ -21,10 +21,13 @@
 package zap_test
 
 import (
+	"errors"
+	"io/ioutil"
 	"testing"
 
 	"github.com/uber-go/zap"
 	"github.com/uber-go/zap/spy"
+	"github.com/uber-go/zap/spywrite"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -140,6 +143,71 @@ func TestTee_Panic(t *testing.T) {
 	}, sink2.Logs())
 }
 
-// XXX: we cannot presently write `func TestTee_Fatal(t *testing.T)`,
-// because we can't have both a spy logger and an exit stub without a
-// dependency cycle.
+func TestTee_Fatal(t *testing.T) {
+	var codes []int
+	exit := zap.ExitFunc(func(code int) { codes = append(codes, code) })
+	log1, sink1 := spy.New(exit, zap.ExitCode(3))
+	log2, sink2 := spy.New(exit, zap.ExitCode(4))
+	log := zap.Tee(log1, log2)
+
+	log.Fatal("foo")
+	log.Check(zap.FatalLevel, "bar").Write()
+	log.Log(zap.FatalLevel, "baz")
+
+	fatal := func(msg string) spy.Log {
+		return spy.Log{Level: zap.FatalLevel, Msg: msg, Fields: []zap.Field{}}
+	}
+	expected := []spy.Log{fatal("foo"), fatal("bar"), fatal("baz")}
+	assert.Equal(t, expected, sink1.Logs(), "Unexpected output from the first sub-logger.")
+	assert.Equal(t, expected, sink2.Logs(), "Unexpected output from the second sub-logger.")
+	assert.Equal(t, []int{3, 3}, codes, "Expected to exit using the first sub-logger's settings.")
+	assert.Equal(t, 2, sink2.Syncs(), "Expected every sub-logger to be synced before exiting.")
+}
+
+func TestTeeNamed(t *testing.T) {
+	log1, sink1 := spy.New(zap.DebugLevel)
+	log2, sink2 := spy.New(zap.DebugLevel)
+	zap.Tee(log1, log2).Named("api").Named("http").Info("request")
+
+	expected := []spy.Log{{
+		Level:  zap.InfoLevel,
+		Name:   "api.http",
+		Msg:    "request",
+		Fields: []zap.Field{},
+	}}
+	assert.Equal(t, expected, sink1.Logs(), "Expected the name to reach the first logger.")
+	assert.Equal(t, expected, sink2.Logs(), "Expected the name to reach the second logger.")
+}
+
+func TestTeeDPanic(t *testing.T) {
+	dev, devSink := spy.New(zap.DebugLevel, zap.Development())
+	prod, prodSink := spy.New(zap.DebugLevel)
+
+	assert.NotPanics(t, func() { zap.Tee(prod, prod).DPanic("prod") }, "Expected no panic without development sub-loggers.")
+	assert.Panics(t, func() { zap.Tee(dev, prod).DPanic("mixed") }, "Expected a panic with any development sub-logger.")
+	assert.Panics(t, func() { zap.Tee(prod, dev).Check(zap.DPanicLevel, "checked").Write() }, "Expected Check(DPanicLevel) to panic too.")
+	assert.NotPanics(t, func() { zap.Tee(dev, prod).Log(zap.DPanicLevel, "logged") }, "Log(DPanicLevel) should never panic.")
+
+	dpanic := func(msg string) spy.Log {
+		return spy.Log{Level: zap.DPanicLevel, Msg: msg, Fields: []zap.Field{}}
+	}
+	assert.Equal(t, []spy.Log{dpanic("mixed"), dpanic("checked"), dpanic("logged")}, devSink.Logs(), "Unexpected output from development sub-logger.")
+	assert.Equal(t, []spy.Log{
+		dpanic("prod"), dpanic("prod"), dpanic("mixed"), dpanic("checked"), dpanic("logged"),
+	}, prodSink.Logs(), "Expected every sub-logger to receive the message before panicking.")
+}
+
+func TestTeeSync(t *testing.T) {
+	log1, sink1 := spy.New()
+	log2, sink2 := spy.New()
+	assert.NoError(t, zap.Tee(log1, log2).Named("foo").Sync(), "Unexpected error syncing Tee.")
+	assert.Equal(t, 1, sink1.Syncs(), "Expected to sync the first logger.")
+	assert.Equal(t, 1, sink2.Syncs(), "Expected to sync the second logger.")
+
+	failing := &spywrite.WriteSyncer{Writer: ioutil.Discard}
+	failing.SetError(errors.New("failed"))
+	tee := zap.Tee(zap.New(zap.NewJSONEncoder(), zap.Output(failing)), log1, zap.New(zap.NewJSONEncoder(), zap.Output(failing)))
+	err := tee.Sync()
+	assert.Equal(t, "failed failed ", err.Error(), "Expected errors from all failing sub-loggers.")
+	assert.Equal(t, 2, sink1.Syncs(), "Expected to sync every sub-logger despite failures.")
+}
@@ -0,0 +1,567 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var templatePool = sync.Pool{New: func() interface{} {
+	return &templateEncoder{
+		bytes: make([]byte, 0, _initialBufSize),
+	}
+}}
+
+type templatePartKind int
+
+const (
+	literalPart templatePartKind = iota
+	timePart
+	levelPart
+	messagePart
+	namePart
+	fieldPart
+	fieldsPart
+)
+
+type templatePart struct {
+	kind templatePartKind
+	// Literal text for literalPart, or the layout for timePart.
+	text string
+	// The slot of the named field for fieldPart.
+	slot int
+	// The style for levelPart.
+	levelStyle string
+}
+
+// A lineTemplate is a compiled template, shared by an encoder and all its
+// clones.
+type lineTemplate struct {
+	parts []templatePart
+	slots map[string]int
+	// How to render fields not referenced by name, both in the {fields}
+	// placeholder and within nested objects.
+	json bool
+
+	// Limits set by TemplateOptions.
+	maxMessage int
+	maxString  int
+	maxDepth   int
+}
+
+// A templateSpan records where a top-level field lives in an encoder's
+// buffer, and whether it's referenced by name.
+type templateSpan struct {
+	slot  int // -1 for fields that aren't referenced by name
+	start int
+}
+
+type templateEncoder struct {
+	*lineTemplate
+
+	bytes []byte
+	spans []templateSpan
+	depth int
+	name  string
+	// Set while writing the value of a named field, which is rendered without
+	// quotes or escaping.
+	raw bool
+}
+
+// NewTemplateEncoder creates an encoder that renders each entry as a single
+// line laid out according to the supplied template. The template is compiled
+// once, and entries are rendered directly into pooled buffers. For example,
+// the template
+//   {time:2006-01-02T15:04:05Z07:00} {level:upper} [{field:svc}] {msg} {fields}
+// produces lines like
+//   2016-01-02T15:04:05Z INFO [billing] Charged card. amount=42 currency=USD
+//
+// Templates may contain the following placeholders:
+//   {time}            the entry time, formatted as RFC3339
+//   {time:LAYOUT}     the entry time, formatted with a time.Format layout
+//   {level}           the level, in lower case (e.g., "info")
+//   {level:STYLE}     the level, in "lower", "upper" (e.g., "INFO"), or
+//                     "short" (e.g., "I") style
+//   {msg}             the log message
+//   {caller}          the value of the "caller" field (see AddCallerField)
+//   {name}            the name of a Named logger
+//   {field:KEY}       the value of the field with the given key
+//   {fields}          all fields not referenced elsewhere in the template, in
+//                     logfmt style (e.g., `k=v s="a b"`)
+//   {fields:logfmt}   the same as {fields}
+//   {fields:json}     the unreferenced fields as a JSON object
+// Use {{ and }} for literal braces. Named fields that aren't present render as
+// empty strings, and their values are written without quoting. Control
+// characters in messages, names, and named fields are escaped (e.g., a newline
+// becomes \n), so that each entry stays on a single line.
+// Trailing spaces are trimmed from each line.
+//
+// NewTemplateEncoder returns an error if the template is malformed.
+func NewTemplateEncoder(format string, options ...TemplateOption) (Encoder, error) {
+	tmpl, err := compileTemplate(format)
+	if err != nil {
+		return nil, err
+	}
+	enc := templatePool.Get().(*templateEncoder)
+	enc.truncate()
+	enc.lineTemplate = tmpl
+	for _, opt := range options {
+		opt.apply(enc)
+	}
+	return enc, nil
+}
+
+func compileTemplate(format string) (*lineTemplate, error) {
+	tmpl := &lineTemplate{slots: make(map[string]int)}
+	var literal []byte
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c == '}' {
+			if i+1 < len(format) && format[i+1] == '}' {
+				literal = append(literal, '}')
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("unexpected '}' at offset %d in template %q", i, format)
+		}
+		if c != '{' {
+			literal = append(literal, c)
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '{' {
+			literal = append(literal, '{')
+			i++
+			continue
+		}
+		end := strings.IndexByte(format[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed placeholder at offset %d in template %q", i, format)
+		}
+		if len(literal) > 0 {
+			tmpl.parts = append(tmpl.parts, templatePart{kind: literalPart, text: string(literal)})
+			literal = literal[:0]
+		}
+		part, err := tmpl.compilePlaceholder(format[i+1 : i+end])
+		if err != nil {
+			return nil, fmt.Errorf("%v in template %q", err, format)
+		}
+		tmpl.parts = append(tmpl.parts, part)
+		i += end
+	}
+	if len(literal) > 0 {
+		tmpl.parts = append(tmpl.parts, templatePart{kind: literalPart, text: string(literal)})
+	}
+	return tmpl, nil
+}
+
+func (tmpl *lineTemplate) compilePlaceholder(p string) (templatePart, error) {
+	name, arg := p, ""
+	hasArg := false
+	if idx := strings.IndexByte(p, ':'); idx >= 0 {
+		name, arg, hasArg = p[:idx], p[idx+1:], true
+	}
+
+	switch name {
+	case "time":
+		if !hasArg {
+			arg = time.RFC3339
+		}
+		return templatePart{kind: timePart, text: arg}, nil
+	case "level":
+		switch arg {
+		case "", "lower", "upper", "short":
+			return templatePart{kind: levelPart, levelStyle: arg}, nil
+		}
+		return templatePart{}, fmt.Errorf("unknown level style %q", arg)
+	case "msg":
+		if !hasArg {
+			return templatePart{kind: messagePart}, nil
+		}
+	case "caller":
+		if !hasArg {
+			return tmpl.fieldPart(_callerKey), nil
+		}
+	case "name":
+		if !hasArg {
+			return templatePart{kind: namePart}, nil
+		}
+	case "field":
+		if arg != "" {
+			return tmpl.fieldPart(arg), nil
+		}
+		return templatePart{}, fmt.Errorf("placeholder {%s} must name a field", p)
+	case "fields":
+		switch arg {
+		case "", "logfmt":
+			return templatePart{kind: fieldsPart}, nil
+		case "json":
+			tmpl.json = true
+			return templatePart{kind: fieldsPart}, nil
+		}
+		return templatePart{}, fmt.Errorf("unknown fields style %q", arg)
+	}
+	return templatePart{}, fmt.Errorf("unknown placeholder {%s}", p)
+}
+
+func (tmpl *lineTemplate) fieldPart(key string) templatePart {
+	slot, ok := tmpl.slots[key]
+	if !ok {
+		slot = len(tmpl.slots)
+		tmpl.slots[key] = slot
+	}
+	return templatePart{kind: fieldPart, slot: slot}
+}
+
+func (enc *templateEncoder) Free() {
+	if cap(enc.bytes) > _maxPooledBufSize {
+		return
+	}
+	templatePool.Put(enc)
+}
+
+func (enc *templateEncoder) AddString(key, val string) {
+	enc.addKey(key)
+	enc.addString(truncateString(val, enc.maxString))
+	enc.raw = false
+}
+
+func (enc *templateEncoder) AddBool(key string, val bool) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendBool(enc.bytes, val)
+	enc.raw = false
+}
+
+func (enc *templateEncoder) AddInt(key string, val int) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *templateEncoder) AddInt64(key string, val int64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendInt(enc.bytes, val, 10)
+	enc.raw = false
+}
+
+func (enc *templateEncoder) AddUint(key string, val uint) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *templateEncoder) AddUint64(key string, val uint64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendUint(enc.bytes, val, 10)
+	enc.raw = false
+}
+
+func (enc *templateEncoder) AddUintptr(key string, val uintptr) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *templateEncoder) AddFloat64(key string, val float64) {
+	enc.addKey(key)
+	switch {
+	case math.IsNaN(val):
+		enc.addString("NaN")
+	case math.IsInf(val, 1):
+		enc.addString("+Inf")
+	case math.IsInf(val, -1):
+		enc.addString("-Inf")
+	default:
+		enc.bytes = strconv.AppendFloat(enc.bytes, val, 'f', -1, 64)
+	}
+	enc.raw = false
+}
+
+func (enc *templateEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	enc.addKey(key)
+	enc.raw = false
+	if enc.maxDepth > 0 && enc.depth >= enc.maxDepth {
+		enc.bytes = append(enc.bytes, "{}"...)
+		return errMaxDepth
+	}
+	enc.depth++
+	enc.bytes = append(enc.bytes, '{')
+	err := obj.MarshalLog(enc)
+	enc.bytes = append(enc.bytes, '}')
+	enc.depth--
+	return err
+}
+
+func (enc *templateEncoder) AddObject(key string, obj interface{}) error {
+	if !enc.json {
+		enc.AddString(key, fmt.Sprintf("%+v", obj))
+		return nil
+	}
+	marshaled, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if enc.maxString > 0 && len(marshaled) > enc.maxString {
+		enc.AddString(key, string(marshaled))
+		return nil
+	}
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, marshaled...)
+	enc.raw = false
+	return nil
+}
+
+func (enc *templateEncoder) Clone() Encoder {
+	clone := templatePool.Get().(*templateEncoder)
+	clone.truncate()
+	clone.lineTemplate = enc.lineTemplate
+	clone.bytes = append(clone.bytes, enc.bytes...)
+	clone.spans = append(clone.spans, enc.spans...)
+	clone.name = enc.name
+	return clone
+}
+
+func (enc *templateEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time.Time) error {
+	if sink == nil {
+		return errNilSink
+	}
+
+	final := templatePool.Get().(*templateEncoder)
+	final.truncate()
+	for _, p := range enc.parts {
+		switch p.kind {
+		case literalPart:
+			final.bytes = append(final.bytes, p.text...)
+		case timePart:
+			final.bytes = t.AppendFormat(final.bytes, p.text)
+		case levelPart:
+			final.bytes = appendLevel(final.bytes, lvl, p.levelStyle)
+		case messagePart:
+			final.bytes = appendEscapedText(final.bytes, truncateString(msg, enc.maxMessage))
+		case namePart:
+			final.bytes = appendEscapedText(final.bytes, enc.name)
+		case fieldPart:
+			final.bytes = enc.appendNamed(final.bytes, p.slot)
+		case fieldsPart:
+			final.bytes = enc.appendUnnamed(final.bytes)
+		}
+	}
+	for len(final.bytes) > 0 && final.bytes[len(final.bytes)-1] == ' ' {
+		final.bytes = final.bytes[:len(final.bytes)-1]
+	}
+	final.bytes = append(final.bytes, '\n')
+
+	err := writeEntry(sink, final.bytes)
+	final.Free()
+	return err
+}
+
+// appendNamed appends the value of the last field added in the given slot.
+func (enc *templateEncoder) appendNamed(buf []byte, slot int) []byte {
+	for i := len(enc.spans) - 1; i >= 0; i-- {
+		if enc.spans[i].slot == slot {
+			return append(buf, enc.bytes[enc.spans[i].start:enc.spanEnd(i)]...)
+		}
+	}
+	return buf
+}
+
+// appendUnnamed appends all the fields that aren't referenced by name.
+func (enc *templateEncoder) appendUnnamed(buf []byte) []byte {
+	sep := byte(' ')
+	if enc.json {
+		sep = ','
+		buf = append(buf, '{')
+	}
+	first := true
+	for i, s := range enc.spans {
+		if s.slot >= 0 {
+			continue
+		}
+		if !first {
+			buf = append(buf, sep)
+		}
+		first = false
+		buf = append(buf, enc.bytes[s.start:enc.spanEnd(i)]...)
+	}
+	if enc.json {
+		buf = append(buf, '}')
+	}
+	return buf
+}
+
+func (enc *templateEncoder) spanEnd(i int) int {
+	if i+1 < len(enc.spans) {
+		return enc.spans[i+1].start
+	}
+	return len(enc.bytes)
+}
+
+func (enc *templateEncoder) truncate() {
+	enc.bytes = enc.bytes[:0]
+	enc.spans = enc.spans[:0]
+	enc.depth = 0
+	enc.raw = false
+	enc.name = ""
+}
+
+func (enc *templateEncoder) setName(name string) {
+	enc.name = name
+}
+
+func (enc *templateEncoder) addKey(key string) {
+	if enc.depth == 0 {
+		slot, ok := enc.slots[key]
+		if !ok {
+			slot = -1
+		}
+		enc.spans = append(enc.spans, templateSpan{slot: slot, start: len(enc.bytes)})
+		if ok {
+			// Named fields are rendered without their keys.
+			enc.raw = true
+			return
+		}
+	} else if last := len(enc.bytes) - 1; enc.bytes[last] != '{' {
+		if enc.json {
+			enc.bytes = append(enc.bytes, ',')
+		} else {
+			enc.bytes = append(enc.bytes, ' ')
+		}
+	}
+
+	if enc.json {
+		enc.bytes = appendJSONString(enc.bytes, key)
+		enc.bytes = append(enc.bytes, ':')
+		return
+	}
+	enc.bytes = append(enc.bytes, key...)
+	enc.bytes = append(enc.bytes, '=')
+}
+
+func (enc *templateEncoder) addString(s string) {
+	switch {
+	case enc.raw:
+		enc.bytes = appendEscapedText(enc.bytes, s)
+	case enc.json || needsLogfmtQuotes(s):
+		enc.bytes = appendJSONString(enc.bytes, s)
+	default:
+		enc.bytes = append(enc.bytes, s...)
+	}
+}
+
+// appendJSONString appends a quoted, JSON-escaped string.
+func appendJSONString(buf []byte, s string) []byte {
+	enc := jsonEncoder{bytes: buf}
+	enc.bytes = append(enc.bytes, '"')
+	enc.safeAddString(s)
+	enc.bytes = append(enc.bytes, '"')
+	return enc.bytes
+}
+
+// appendEscapedText appends an unquoted string, escaping control characters
+// (such as newlines) so that they can't break up a line of output. Other
+// characters, including quotes and backslashes, are written as-is.
+func appendEscapedText(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if b >= ' ' && b != 0x7f {
+			buf = append(buf, b)
+			continue
+		}
+		switch b {
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			buf = append(buf, `\u00`...)
+			buf = append(buf, _hex[b>>4], _hex[b&0xF])
+		}
+	}
+	return buf
+}
+
+// needsLogfmtQuotes reports whether a logfmt value must be quoted.
+func needsLogfmtQuotes(s string) bool {
+	if s == "" {
+		return true
+	}
+	for i := 0; i < len(s); i++ {
+		if b := s[i]; b <= ' ' || b == '=' || b == '"' || b == '{' || b == '}' || b == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+func appendLevel(buf []byte, lvl Level, style string) []byte {
+	switch style {
+	case "upper":
+		for _, c := range []byte(lvl.String()) {
+			if 'a' <= c && c <= 'z' {
+				c -= 'a' - 'A'
+			}
+			buf = append(buf, c)
+		}
+		return buf
+	case "short":
+		return appendShortLevel(buf, lvl)
+	default:
+		return append(buf, lvl.String()...)
+	}
+}
+
+// A TemplateOption is used to set options for a template encoder.
+type TemplateOption interface {
+	apply(*templateEncoder)
+}
+
+type templateOptionFunc func(*templateEncoder)
+
+func (opt templateOptionFunc) apply(enc *templateEncoder) {
+	opt(enc)
+}
+
+// TemplateMaxMessageLength truncates log messages longer than n bytes. See
+// MaxMessageLength for details.
+func TemplateMaxMessageLength(n int) TemplateOption {
+	return templateOptionFunc(func(enc *templateEncoder) {
+		enc.maxMessage = n
+	})
+}
+
+// TemplateMaxStringLength truncates string fields longer than n bytes,
+// including named fields and Object fields. See MaxStringLength for details.
+func TemplateMaxStringLength(n int) TemplateOption {
+	return templateOptionFunc(func(enc *templateEncoder) {
+		enc.maxString = n
+	})
+}
+
+// TemplateMaxNestingDepth limits how deeply LogMarshalers may nest objects.
+// See MaxNestingDepth for details.
+func TemplateMaxNestingDepth(n int) TemplateOption {
+	return templateOptionFunc(func(enc *templateEncoder) {
+		enc.maxDepth = n
+	})
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/uber-go/zap/spywrite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTemplateEncoder(t testing.TB, format string, opts ...TemplateOption) *templateEncoder {
+	enc, err := NewTemplateEncoder(format, opts...)
+	require.NoError(t, err, "Unexpected error compiling template %q.", format)
+	return enc.(*templateEncoder)
+}
+
+func assertTemplateEntry(t testing.TB, expected string, enc Encoder, msg string, lvl Level) {
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, msg, lvl, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, expected+"\n", sink.String(), "Unexpected output from template encoder.")
+}
+
+func TestTemplateEncoderPlaceholders(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"plain text", "plain text"},
+		{"{{literal}} braces", "{literal} braces"},
+		{"{time}", "1970-01-01T00:00:00Z"},
+		{"{time:2006-01-02 15:04:05}", "1970-01-01 00:00:00"},
+		{"{level}", "warn"},
+		{"{level:lower}", "warn"},
+		{"{level:upper}", "WARN"},
+		{"{level:short}", "W"},
+		{"{msg}", "Hello, world."},
+		{"{caller}", "foo.go:42"},
+		{"{name}", "api.http"},
+		{"{field:svc}", "billing"},
+		{"{field:missing}|", "|"},
+		{"{fields}", `svc=billing caller=foo.go:42 n=1 s="a b"`},
+		{"{caller} {name} {fields:logfmt}", `foo.go:42 api.http svc=billing n=1 s="a b"`},
+		{"{fields:json} {field:svc}", `{"caller":"foo.go:42","n":1,"s":"a b"} billing`},
+		{
+			"{time:2006-01-02T15:04:05Z07:00} {level:upper} [{field:svc}] {msg} {fields}",
+			`1970-01-01T00:00:00Z WARN [billing] Hello, world. caller=foo.go:42 n=1 s="a b"`,
+		},
+	}
+
+	for _, tt := range tests {
+		enc := newTemplateEncoder(t, tt.format)
+		enc.AddString("svc", "billing")
+		enc.AddString("caller", "foo.go:42")
+		enc.AddInt("n", 1)
+		enc.setName("api.http")
+		enc.AddString("s", "a b")
+		assertTemplateEntry(t, tt.expected, enc, "Hello, world.", WarnLevel)
+		enc.Free()
+	}
+}
+
+func TestTemplateEncoderFields(t *testing.T) {
+	tests := []struct {
+		desc   string
+		f      func(Encoder)
+		logfmt string
+		json   string
+		named  string
+	}{
+		{"string", func(e Encoder) { e.AddString("k", "v") }, "k=v", `{"k":"v"}`, "v"},
+		{"empty string", func(e Encoder) { e.AddString("k", "") }, `k=""`, `{"k":""}`, ""},
+		{"quoted string", func(e Encoder) { e.AddString("k", `a="b"`) }, `k="a=\"b\""`, `{"k":"a=\"b\""}`, `a="b"`},
+		{"bool", func(e Encoder) { e.AddBool("k", true) }, "k=true", `{"k":true}`, "true"},
+		{"int", func(e Encoder) { e.AddInt("k", -42) }, "k=-42", `{"k":-42}`, "-42"},
+		{"uint", func(e Encoder) { e.AddUint("k", 42) }, "k=42", `{"k":42}`, "42"},
+		{"uintptr", func(e Encoder) { e.AddUintptr("k", 42) }, "k=42", `{"k":42}`, "42"},
+		{"float", func(e Encoder) { e.AddFloat64("k", 1.5) }, "k=1.5", `{"k":1.5}`, "1.5"},
+		{"NaN", func(e Encoder) { e.AddFloat64("k", math.NaN()) }, "k=NaN", `{"k":"NaN"}`, "NaN"},
+		{"marshaler", func(e Encoder) {
+			e.AddMarshaler("k", LogMarshalerFunc(func(kv KeyValue) error {
+				kv.AddString("a", "b c")
+				kv.AddInt("d", 1)
+				return nil
+			}))
+		}, `k={a="b c" d=1}`, `{"k":{"a":"b c","d":1}}`, `{a="b c" d=1}`},
+		{"object", func(e Encoder) {
+			e.AddObject("k", []int{1, 2})
+		}, `k="[1 2]"`, `{"k":[1,2]}`, "[1 2]"},
+	}
+
+	for _, tt := range tests {
+		for _, format := range []string{"{fields}", "{fields:json}", "{field:k}"} {
+			enc := newTemplateEncoder(t, format)
+			tt.f(enc)
+			expected := map[string]string{"{fields}": tt.logfmt, "{fields:json}": tt.json, "{field:k}": tt.named}[format]
+			assertTemplateEntry(t, expected, enc, "", InfoLevel)
+			enc.Free()
+		}
+	}
+}
+
+func TestTemplateEncoderClone(t *testing.T) {
+	parent := newTemplateEncoder(t, "[{field:svc}] {msg} {fields}")
+	parent.AddString("svc", "parent")
+	parent.AddInt("a", 1)
+
+	clone := parent.Clone()
+	clone.AddString("svc", "child")
+	clone.AddInt("b", 2)
+
+	assertTemplateEntry(t, "[parent] hi a=1", parent, "hi", InfoLevel)
+	assertTemplateEntry(t, "[child] hi a=1 b=2", clone, "hi", InfoLevel)
+}
+
+func TestTemplateEncoderTrimsTrailingSpaces(t *testing.T) {
+	enc := newTemplateEncoder(t, "{level:upper} {msg} {fields}")
+	assertTemplateEntry(t, "INFO hi", enc, "hi", InfoLevel)
+	assertTemplateEntry(t, "INFO", enc, "", InfoLevel)
+}
+
+func TestTemplateEncoderErrors(t *testing.T) {
+	tests := []struct {
+		format string
+		err    string
+	}{
+		{"{msg", "unclosed placeholder at offset 0"},
+		{"msg}", "unexpected '}' at offset 3"},
+		{"{nope}", "unknown placeholder {nope}"},
+		{"{msg:foo}", "unknown placeholder {msg:foo}"},
+		{"{level:fancy}", `unknown level style "fancy"`},
+		{"{fields:xml}", `unknown fields style "xml"`},
+		{"{field}", "placeholder {field} must name a field"},
+		{"{field:}", "placeholder {field:} must name a field"},
+	}
+
+	for _, tt := range tests {
+		_, err := NewTemplateEncoder(tt.format)
+		if assert.Error(t, err, "Expected an error compiling template %q.", tt.format) {
+			assert.Contains(t, err.Error(), tt.err, "Unexpected error compiling template %q.", tt.format)
+		}
+	}
+}
+
+func TestTemplateWriteEntryFailure(t *testing.T) {
+	enc := newTemplateEncoder(t, "{msg}")
+	tests := []struct {
+		sink *spywrite.WriteSyncer
+		msg  string
+	}{
+		{&spywrite.WriteSyncer{Writer: spywrite.FailWriter{}}, "Expected an error when writing to sink fails."},
+		{&spywrite.WriteSyncer{Writer: spywrite.ShortWriter{}}, "Expected an error on partial writes to sink."},
+	}
+	for _, tt := range tests {
+		assert.Error(t, enc.WriteEntry(tt.sink, "hello", InfoLevel, time.Unix(0, 0)), tt.msg)
+	}
+	assert.Equal(t, errNilSink, enc.WriteEntry(nil, "hello", InfoLevel, epoch), "Expected an error writing to a nil sink.")
+}
+
+func TestTemplateEncoderCallerField(t *testing.T) {
+	enc := newTemplateEncoder(t, "{caller} {msg}")
+	buf := &testBuffer{}
+	logger := New(enc, Output(buf), AddCallerField())
+	logger.Info("Callers.")
+	assert.Regexp(t, regexp.MustCompile(`^template_encoder_test.go:\d+ Callers\.$`), buf.Stripped(), "Expected caller in output.")
+}
+
+func TestTemplateEncoderLimits(t *testing.T) {
+	enc := newTemplateEncoder(t, "{msg} [{field:svc}] {fields:json}",
+		TemplateMaxMessageLength(5),
+		TemplateMaxStringLength(3),
+		TemplateMaxNestingDepth(1),
+	)
+	enc.AddString("svc", "billing")
+	enc.AddString("s", "abcdef")
+	require.NoError(t, enc.AddObject("obj", []int{1, 2, 3}), "Unexpected error adding an object.")
+	err := enc.AddMarshaler("outer", LogMarshalerFunc(func(kv KeyValue) error {
+		return kv.AddMarshaler("inner", LogMarshalerFunc(func(kv KeyValue) error {
+			kv.AddInt("deep", 1)
+			return nil
+		}))
+	}))
+	assert.Equal(t, errMaxDepth, err, "Expected an error nesting objects too deeply.")
+
+	assertTemplateEntry(t,
+		`Hello…(truncated 8 bytes) [bil…(truncated 4 bytes)] {"s":"abc…(truncated 3 bytes)","obj":"[1,…(truncated 4 bytes)","outer":{"inner":{}}}`,
+		enc.Clone(), "Hello, world.", InfoLevel,
+	)
+}
+
+func TestTemplateEncoderEscapesControlCharacters(t *testing.T) {
+	enc := newTemplateEncoder(t, "{name} {msg} [{field:svc}] {fields}")
+	enc.setName("api\nhttp")
+	enc.AddString("svc", "bill\ning")
+	enc.AddString("body", "a\nb")
+	assertTemplateEntry(t,
+		`api\nhttp line one\nline two\ttab\x [bill\ning] body="a\nb"`,
+		enc, "line one\nline two\ttab\\x", InfoLevel,
+	)
+	assertTemplateEntry(t, `bell\u0007 del\u007f`, newTemplateEncoder(t, "{msg}"), "bell\a del\x7f", InfoLevel)
+}
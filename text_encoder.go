@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -38,15 +39,28 @@ type textEncoder struct {
 	bytes       []byte
 	timeFmt     string
 	firstNested bool
+	sortKeys    bool
+	multiline   bool
+	depth       int
+	spans       []fieldSpan
+	trailer     []byte
+	name        string
+	maxMessage  int
+	maxString   int
+	maxDepth    int
 }
 
 // NewTextEncoder creates a line-oriented text encoder whose output is optimized
 // for human, rather than machine, consumption. By default, the encoder uses
-// RFC3339-formatted timestamps.
+// RFC3339-formatted timestamps. The names of Named loggers precede the
+// message, followed by a colon.
 func NewTextEncoder(options ...TextOption) Encoder {
 	enc := textPool.Get().(*textEncoder)
 	enc.truncate()
 	enc.timeFmt = time.RFC3339
+	enc.sortKeys = false
+	enc.multiline = false
+	enc.maxMessage, enc.maxString, enc.maxDepth = 0, 0, 0
 	for _, opt := range options {
 		opt.apply(enc)
 	}
@@ -54,10 +68,18 @@ func NewTextEncoder(options ...TextOption) Encoder {
 }
 
 func (enc *textEncoder) Free() {
+	if cap(enc.bytes) > _maxPooledBufSize || cap(enc.trailer) > _maxPooledBufSize {
+		return
+	}
 	textPool.Put(enc)
 }
 
 func (enc *textEncoder) AddString(key, val string) {
+	val = truncateString(val, enc.maxString)
+	if enc.multiline && enc.depth == 0 && strings.IndexByte(val, '\n') >= 0 {
+		enc.addContinuation(key, val)
+		return
+	}
 	enc.addKey(key)
 	enc.bytes = append(enc.bytes, val...)
 }
@@ -98,9 +120,20 @@ func (enc *textEncoder) AddFloat64(key string, val float64) {
 
 func (enc *textEncoder) AddMarshaler(key string, obj LogMarshaler) error {
 	enc.addKey(key)
+	if enc.maxDepth > 0 && enc.depth >= enc.maxDepth {
+		enc.bytes = append(enc.bytes, "{}"...)
+		return errMaxDepth
+	}
 	enc.firstNested = true
 	enc.bytes = append(enc.bytes, '{')
+	enc.depth++
+	mark := len(enc.spans)
 	err := obj.MarshalLog(enc)
+	if enc.sortKeys {
+		sortTrailingFields(enc.bytes, enc.spans[mark:], ' ')
+		enc.spans = enc.spans[:mark]
+	}
+	enc.depth--
 	enc.bytes = append(enc.bytes, '}')
 	enc.firstNested = false
 	return err
@@ -117,6 +150,14 @@ func (enc *textEncoder) Clone() Encoder {
 	clone.bytes = append(clone.bytes, enc.bytes...)
 	clone.timeFmt = enc.timeFmt
 	clone.firstNested = enc.firstNested
+	clone.sortKeys = enc.sortKeys
+	clone.multiline = enc.multiline
+	clone.maxMessage = enc.maxMessage
+	clone.maxString = enc.maxString
+	clone.maxDepth = enc.maxDepth
+	clone.spans = append(clone.spans, enc.spans...)
+	clone.trailer = append(clone.trailer, enc.trailer...)
+	clone.name = enc.name
 	return clone
 }
 
@@ -129,28 +170,44 @@ func (enc *textEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time
 	final.truncate()
 	enc.addLevel(final, lvl)
 	enc.addTime(final, t)
+	enc.addName(final)
 	enc.addMessage(final, msg)
 
 	if len(enc.bytes) > 0 {
 		final.bytes = append(final.bytes, ' ')
-		final.bytes = append(final.bytes, enc.bytes...)
+		if enc.sortKeys {
+			final.spans = append(final.spans[:0], enc.spans...)
+			measureSpans(final.spans, len(enc.bytes))
+			sortSpans(enc.bytes, final.spans)
+			final.bytes = appendSpans(final.bytes, enc.bytes, final.spans, ' ')
+		} else {
+			final.bytes = append(final.bytes, enc.bytes...)
+		}
 	}
+	final.bytes = append(final.bytes, enc.trailer...)
 	final.bytes = append(final.bytes, '\n')
 
-	expectedBytes := len(final.bytes)
-	n, err := sink.Write(final.bytes)
+	err := writeEntry(sink, final.bytes)
 	final.Free()
-	if err != nil {
-		return err
-	}
-	if n != expectedBytes {
-		return fmt.Errorf("incomplete write: only wrote %v of %v bytes", n, expectedBytes)
-	}
-	return nil
+	return err
 }
 
 func (enc *textEncoder) truncate() {
 	enc.bytes = enc.bytes[:0]
+	enc.spans = enc.spans[:0]
+	enc.trailer = enc.trailer[:0]
+	enc.depth = 0
+	enc.name = ""
+}
+
+// verboseErrors implements verboseErrorEncoder. Verbose error output is only
+// worth adding when it can go on continuation lines.
+func (enc *textEncoder) verboseErrors() bool {
+	return enc.multiline && enc.depth == 0
+}
+
+func (enc *textEncoder) setName(name string) {
+	enc.name = name
 }
 
 func (enc *textEncoder) addKey(key string) {
@@ -160,29 +217,62 @@ func (enc *textEncoder) addKey(key string) {
 	} else {
 		enc.firstNested = false
 	}
+	start := len(enc.bytes)
 	enc.bytes = append(enc.bytes, key...)
+	if enc.sortKeys {
+		enc.spans = append(enc.spans, fieldSpan{
+			start:    start,
+			keyStart: start,
+			keyEnd:   len(enc.bytes),
+		})
+	}
 	enc.bytes = append(enc.bytes, '=')
 }
 
+// addContinuation buffers a multi-line value so that WriteEntry can render it
+// after the rest of the entry: the key on its own indented line, followed by
+// each line of the value indented one level further.
+func (enc *textEncoder) addContinuation(key, val string) {
+	enc.trailer = append(enc.trailer, "\n\t"...)
+	enc.trailer = append(enc.trailer, key...)
+	enc.trailer = append(enc.trailer, ':')
+	val = strings.TrimRight(val, "\n")
+	for {
+		enc.trailer = append(enc.trailer, "\n\t\t"...)
+		i := strings.IndexByte(val, '\n')
+		if i < 0 {
+			enc.trailer = append(enc.trailer, val...)
+			return
+		}
+		enc.trailer = append(enc.trailer, val[:i]...)
+		val = val[i+1:]
+	}
+}
+
 func (enc *textEncoder) addLevel(final *textEncoder, lvl Level) {
 	final.bytes = append(final.bytes, '[')
+	final.bytes = appendShortLevel(final.bytes, lvl)
+	final.bytes = append(final.bytes, ']')
+}
+
+// appendShortLevel appends a single-character abbreviation of the level.
+func appendShortLevel(buf []byte, lvl Level) []byte {
 	switch lvl {
 	case DebugLevel:
-		final.bytes = append(final.bytes, 'D')
+		return append(buf, 'D')
 	case InfoLevel:
-		final.bytes = append(final.bytes, 'I')
+		return append(buf, 'I')
 	case WarnLevel:
-		final.bytes = append(final.bytes, 'W')
+		return append(buf, 'W')
 	case ErrorLevel:
-		final.bytes = append(final.bytes, 'E')
+		return append(buf, 'E')
 	case PanicLevel:
-		final.bytes = append(final.bytes, 'P')
+		return append(buf, 'P')
 	case FatalLevel:
-		final.bytes = append(final.bytes, 'F')
+		return append(buf, 'F')
 	default:
-		final.bytes = strconv.AppendInt(final.bytes, int64(lvl), 10)
+		return strconv.AppendInt(buf, int64(lvl), 10)
 	}
-	final.bytes = append(final.bytes, ']')
 }
 
 func (enc *textEncoder) addTime(final *textEncoder, t time.Time) {
@@ -193,9 +283,18 @@ func (enc *textEncoder) addTime(final *textEncoder, t time.Time) {
 	final.bytes = t.AppendFormat(final.bytes, enc.timeFmt)
 }
 
+func (enc *textEncoder) addName(final *textEncoder) {
+	if enc.name == "" {
+		return
+	}
+	final.bytes = append(final.bytes, ' ')
+	final.bytes = append(final.bytes, enc.name...)
+	final.bytes = append(final.bytes, ':')
+}
+
 func (enc *textEncoder) addMessage(final *textEncoder, msg string) {
 	final.bytes = append(final.bytes, ' ')
-	final.bytes = append(final.bytes, msg...)
+	final.bytes = append(final.bytes, truncateString(msg, enc.maxMessage)...)
 }
 
 // A TextOption is used to set options for a text encoder.
@@ -221,3 +320,48 @@ func TextTimeFormat(layout string) TextOption {
 func TextNoTime() TextOption {
 	return TextTimeFormat("")
 }
+
+// TextSortKeys emits each entry's fields sorted by key, at every level of
+// nesting, rather than in the order they were added. See SortKeys for
+// details.
+func TextSortKeys() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.sortKeys = true
+	})
+}
+
+// TextMultiline renders top-level string fields that span several lines, such
+// as stacktraces, on indented continuation lines after the rest of the entry
+// instead of inline. It also adds the verbose output of top-level Error
+// fields under the "errorVerbose" key (see Error). Values nested inside a
+// LogMarshaler are always written inline.
+func TextMultiline() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.multiline = true
+	})
+}
+
+// TextMaxMessageLength truncates log messages longer than n bytes. See
+// MaxMessageLength for details.
+func TextMaxMessageLength(n int) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.maxMessage = n
+	})
+}
+
+// TextMaxStringLength truncates string fields (including Object fields, which
+// the text encoder renders as strings) longer than n bytes. See
+// MaxStringLength for details.
+func TextMaxStringLength(n int) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.maxString = n
+	})
+}
+
+// TextMaxNestingDepth limits how deeply LogMarshalers may nest objects. See
+// MaxNestingDepth for details.
+func TextMaxNestingDepth(n int) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.maxDepth = n
+	})
+}
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/uber-go/zap/spywrite"
 )
 
@@ -181,6 +182,103 @@ func TestTextClone(t *testing.T) {
 	assert.Equal(t, "baz=bing", string(clone.(*textEncoder).bytes), "Unexpected serialized fields in cloned encoder.")
 }
 
+func TestTextLimits(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextMaxMessageLength(5), TextMaxStringLength(3), TextMaxNestingDepth(1))
+	enc.AddString("s", "abcdef")
+	require.NoError(t, enc.AddObject("obj", []int{1, 2, 3}), "Unexpected error adding an object.")
+	err := enc.AddMarshaler("outer", LogMarshalerFunc(func(kv KeyValue) error {
+		return kv.AddMarshaler("inner", LogMarshalerFunc(func(kv KeyValue) error {
+			kv.AddInt("deep", 1)
+			return nil
+		}))
+	}))
+	assert.Equal(t, errMaxDepth, err, "Expected an error nesting objects too deeply.")
+
+	sink := &testBuffer{}
+	require.NoError(t, enc.Clone().WriteEntry(sink, "Hello, world.", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t,
+		"[I] Hello…(truncated 8 bytes) s=abc…(truncated 3 bytes) obj=[1 …(truncated 4 bytes) outer={inner={}}",
+		sink.Stripped(),
+		"Unexpected output with limits.",
+	)
+}
+
+func TestTextSortKeys(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextSortKeys())
+	enc.AddString("zeta", "z")
+	child := enc.Clone()
+	child.AddMarshaler("nested", LogMarshalerFunc(func(kv KeyValue) error {
+		kv.AddInt("b", 2)
+		kv.AddInt("a", 1)
+		return nil
+	}))
+	child.AddString("alpha", "a")
+
+	sink := &testBuffer{}
+	require.NoError(t, child.WriteEntry(sink, "Sorted.", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[I] Sorted. alpha=a nested={a=1 b=2} zeta=z", sink.Stripped(), "Expected fields to be sorted by key.")
+}
+
+func TestTextMultiline(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextMultiline())
+	enc.AddString("foo", "bar")
+	enc.AddString("stacktrace", "goroutine 1 [running]:\nmain.main()\n\t/tmp/main.go:10\n")
+	child := enc.Clone()
+	child.AddMarshaler("nested", LogMarshalerFunc(func(kv KeyValue) error {
+		kv.AddString("lines", "a\nb")
+		return nil
+	}))
+	child.AddString("errorVerbose", "fail\nat main.go:10")
+
+	sink := &testBuffer{}
+	require.NoError(t, child.WriteEntry(sink, "Failed.", ErrorLevel, epoch), "Unexpected error writing entry.")
+	expected := "[E] Failed. foo=bar nested={lines=a\nb}\n" +
+		"\tstacktrace:\n" +
+		"\t\tgoroutine 1 [running]:\n" +
+		"\t\tmain.main()\n" +
+		"\t\t\t/tmp/main.go:10\n" +
+		"\terrorVerbose:\n" +
+		"\t\tfail\n" +
+		"\t\tat main.go:10\n"
+	assert.Equal(t, expected, sink.String(), "Expected multi-line fields on continuation lines.")
+
+	sink.Reset()
+	require.NoError(t, enc.WriteEntry(sink, "Parent.", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(
+		t,
+		"[I] Parent. foo=bar\n\tstacktrace:\n\t\tgoroutine 1 [running]:\n\t\tmain.main()\n\t\t\t/tmp/main.go:10\n",
+		sink.String(),
+		"Expected children not to affect the parent's continuation lines.",
+	)
+}
+
+func TestTextMultilineVerboseErrors(t *testing.T) {
+	verbose := verboseError{msg: "fail", detail: "fail\nat main.go:10"}
+	tests := []struct {
+		opts     []TextOption
+		err      error
+		expected string
+	}{
+		{nil, verbose, "[E] Failed. error=fail\n"},
+		{[]TextOption{TextMultiline()}, verbose, "[E] Failed. error=fail\n\terrorVerbose:\n\t\tfail\n\t\tat main.go:10\n"},
+		{[]TextOption{TextMultiline()}, verboseError{msg: "fail", detail: "fail"}, "[E] Failed. error=fail\n"},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(append([]TextOption{TextNoTime()}, tt.opts...)...)
+		Error(tt.err).AddTo(enc)
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "Failed.", ErrorLevel, epoch), "Unexpected error writing entry.")
+		assert.Equal(t, tt.expected, sink.String(), "Unexpected output for verbose error.")
+	}
+
+	enc := NewTextEncoder(TextNoTime(), TextMultiline())
+	enc.AddMarshaler("nested", multiFields{Error(verbose)})
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "Failed.", ErrorLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[E] Failed. nested={error=fail}\n", sink.String(), "Expected no verbose output for nested errors.")
+}
+
 func TestTextWriteEntryFailure(t *testing.T) {
 	withTextEncoder(func(enc *textEncoder) {
 		tests := []struct {
@@ -22,6 +22,9 @@ package zap
 
 import (
 	"testing"
+	"time"
+
+	"github.com/uber-go/zap/testutils"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -66,3 +69,35 @@ func TestTextLoggerAddMarshalEmpty(t *testing.T) {
 		assert.Equal(t, "[I] Empty m={} something=val", buf.Stripped(), "Unexpected output from logger")
 	})
 }
+
+func TestTextLoggerClockAndTimeZone(t *testing.T) {
+	clock := testutils.NewFakeClock(time.Date(2016, time.July, 4, 12, 0, 0, 0, time.UTC))
+	est := time.FixedZone("EST", -5*60*60)
+
+	sink := &testBuffer{}
+	logger := New(
+		NewTextEncoder(TextTimeFormat(time.RFC3339)),
+		Output(sink),
+		WithClock(clock),
+		TimeZone(est),
+	)
+	logger.Info("noon")
+	clock.Add(time.Hour)
+	logger.With(String("foo", "bar")).Info("later")
+
+	assert.Equal(t, []string{
+		"[I] 2016-07-04T07:00:00-05:00 noon",
+		"[I] 2016-07-04T08:00:00-05:00 later foo=bar",
+	}, sink.Lines(), "Expected timestamps from the fake clock in the configured zone.")
+}
+
+func TestTextLoggerNamed(t *testing.T) {
+	withTextLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		logger.Named("api").Named("http").Info("request", Int("status", 200))
+		logger.Info("root")
+		assert.Equal(t, []string{
+			"[I] api.http: request status=200",
+			"[I] root",
+		}, buf.Lines(), "Unexpected output from named loggers.")
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	// Encoders don't return buffers larger than this to their pools.
+	_maxPooledBufSize = 64 * 1024
+
+	// Truncated values end with a marker like "…(truncated 42 bytes)".
+	_truncatedPrefix = "…(truncated "
+	_truncatedSuffix = " bytes)"
+	// When fields are dropped to respect MaxEntrySize, the number of dropped
+	// bytes is recorded under this key.
+	_truncatedKey = "truncated"
+	// Room to leave for the truncated field, even when the marker is escaped
+	// and the count is large.
+	_truncatedFieldReserve = 64
+)
+
+// errMaxDepth signals that a LogMarshaler tried to nest objects more deeply
+// than the encoder allows.
+var errMaxDepth = errors.New("exceeded maximum nesting depth")
+
+// truncationPoint returns the largest index no greater than limit at which s
+// can be cut without splitting a UTF-8 sequence.
+func truncationPoint(s string, limit int) int {
+	if limit >= len(s) {
+		return len(s)
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return cut
+}
+
+// truncateString cuts s to at most limit bytes, marking any truncation the
+// same way the JSON encoder does. Non-positive limits disable truncation. It
+// only allocates if s is truncated.
+func truncateString(s string, limit int) string {
+	if limit <= 0 || len(s) <= limit {
+		return s
+	}
+	cut := truncationPoint(s, limit)
+	buf := make([]byte, 0, cut+len(_truncatedPrefix)+len(_truncatedSuffix)+20)
+	buf = append(buf, s[:cut]...)
+	buf = append(buf, _truncatedPrefix...)
+	buf = strconv.AppendInt(buf, int64(len(s)-cut), 10)
+	buf = append(buf, _truncatedSuffix...)
+	return string(buf)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncationPoint(t *testing.T) {
+	tests := []struct {
+		s        string
+		limit    int
+		expected int
+	}{
+		{"", 0, 0},
+		{"abc", 5, 3},
+		{"abc", 3, 3},
+		{"abc", 2, 2},
+		{"a☃", 1, 1},
+		{"a☃", 2, 1},
+		{"a☃", 3, 1},
+		{"a☃", 4, 4},
+		{"☃", 2, 0},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, truncationPoint(tt.s, tt.limit), "Unexpected truncation point for %q with limit %d.", tt.s, tt.limit)
+	}
+}
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		s        string
+		limit    int
+		expected string
+	}{
+		{"abc", 0, "abc"},
+		{"abc", 3, "abc"},
+		{"abcdef", 2, "ab…(truncated 4 bytes)"},
+		{"a☃", 2, "a…(truncated 3 bytes)"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, truncateString(tt.s, tt.limit), "Unexpected result truncating %q to %d bytes.", tt.s, tt.limit)
+	}
+}
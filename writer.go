@@ -67,10 +67,31 @@ type lockedWriteSyncer struct {
 	ws WriteSyncer
 }
 
+// newLockedWriteSyncer wraps ws with a mutex, unless it's already safe for
+// concurrent use because it's locked itself or combines locked WriteSyncers.
 func newLockedWriteSyncer(ws WriteSyncer) WriteSyncer {
+	if isLocked(ws) {
+		return ws
+	}
 	return &lockedWriteSyncer{ws: ws}
 }
 
+func isLocked(ws WriteSyncer) bool {
+	switch ws := ws.(type) {
+	case *lockedWriteSyncer:
+		return true
+	case multiWriteSyncer:
+		for _, w := range ws {
+			if !isLocked(w) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
 func (s *lockedWriteSyncer) Write(bs []byte) (int, error) {
 	s.Lock()
 	n, err := s.ws.Write(bs)
@@ -31,13 +31,22 @@ import (
 
 type zapperBarkFields zwrap.KeyValueMap
 
-// Debarkify wraps bark.Logger to make it compatible with zap's JSON logger
-func Debarkify(bl bark.Logger, lvl zap.Level) zap.Logger {
+// _nameKey is the bark field that holds the name of a Named logger.
+const _nameKey = "logger"
+
+// Debarkify wraps bark.Logger to make it compatible with zap's JSON logger.
+// Since the wrapper has no encoder, the only meaningful options are levels and
+// zap.Development; in development mode, DPanic panics after logging at bark's
+// Error level.
+func Debarkify(bl bark.Logger, lvl zap.Level, options ...zap.Option) zap.Logger {
 	if wrapper, ok := bl.(*barker); ok {
 		return wrapper.zl
 	}
+	opts := make([]zap.Option, 0, len(options)+1)
+	opts = append(opts, lvl)
+	opts = append(opts, options...)
 	return &zapper{
-		Meta: zap.MakeMeta(nil, lvl),
+		Meta: zap.MakeMeta(nil, opts...),
 		bl:   bl,
 	}
 }
@@ -86,6 +95,24 @@ func (z *zapper) With(fields ...zap.Field) zap.Logger {
 	}
 }
 
+// Create a child logger with a dotted name. Since bark loggers don't have
+// names, it's added to the bark logger's fields under the "logger" key.
+func (z *zapper) Named(name string) zap.Logger {
+	meta := z.Meta.Named(name)
+	if meta.Name == z.Meta.Name {
+		return z
+	}
+	return &zapper{
+		Meta: meta,
+		bl:   z.bl.WithField(_nameKey, meta.Name),
+	}
+}
+
+// Sync is a no-op, since bark loggers can't be flushed.
+func (z *zapper) Sync() error {
+	return nil
+}
+
 func (z *zapper) Check(l zap.Level, msg string) *zap.CheckedMessage {
 	return z.Meta.Check(z, l, msg)
 }
@@ -107,8 +134,10 @@ func (z *zapper) Error(msg string, fields ...zap.Field) {
 }
 
 func (z *zapper) DPanic(msg string, fields ...zap.Field) {
-	// TODO: Implement development/DPanic?
-	z.Error(msg, fields...)
+	z.Log(zap.DPanicLevel, msg, fields...)
+	if z.Development {
+		panic(z.Meta.PanicValue(zap.DPanicLevel, msg, fields))
+	}
 }
 
 func (z *zapper) Panic(msg string, fields ...zap.Field) {
@@ -174,9 +174,33 @@ func TestDebark_Methods(t *testing.T) {
 	assert.Panics(t, func() { logger.Panic("msg") })
 }
 
-func TestDebark_Stubs(t *testing.T) {
+func TestDebark_Named(t *testing.T) {
+	logger, buf := newDebark(zap.DebugLevel)
+	logger.Named("api").Named("http").Info("request")
+	assert.Contains(t, buf.String(), "logger=api.http", "Expected the dotted name as a bark field.")
+
+	buf.Reset()
+	logger.Info("root")
+	assert.NotContains(t, buf.String(), "logger=", "Expected the parent to remain unnamed.")
+	assert.Equal(t, logger, logger.Named(""), "Expected an empty name segment to be a no-op.")
+}
+
+func TestDebark_Sync(t *testing.T) {
 	logger, _ := newDebark(zap.DebugLevel)
+	assert.NoError(t, logger.Sync(), "Sync should be a no-op")
+}
+
+func TestDebark_DPanic(t *testing.T) {
+	logger, buf := newDebark(zap.DebugLevel)
 	assert.NotPanics(t, func() { logger.DPanic("msg") })
+	assert.NotEqual(t, 0, buf.Len(), "DPanic should log")
+
+	lr, buf := newLogrus()
+	dev := Debarkify(lr, zap.DebugLevel, zap.Development())
+	assert.Panics(t, func() { dev.DPanic("msg") }, "DPanic should panic in development")
+	assert.Panics(t, func() { dev.Check(zap.DPanicLevel, "msg").Write() }, "Check(DPanicLevel) should panic in development")
+	assert.NotPanics(t, func() { dev.Log(zap.DPanicLevel, "msg") }, "Log(DPanicLevel) should never panic")
+	assert.NotEqual(t, 0, buf.Len(), "DPanic should log before panicking")
 }
 
 func TestDebark_zapToBarkFields(t *testing.T) {
@@ -0,0 +1,439 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zwrap
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/internal/frames"
+	"github.com/uber-go/zap/internal/names"
+)
+
+// An Action is what a Rule does with the entries it matches.
+type Action int
+
+const (
+	// Keep passes matching entries through to the underlying logger.
+	Keep Action = iota + 1
+	// Drop discards matching entries.
+	Drop
+)
+
+// _maxCallerDepth bounds the number of frames searched for the caller's
+// package.
+const _maxCallerDepth = 32
+
+// A Rule declaratively describes a class of log entries. An entry matches a
+// rule if it satisfies all of the rule's non-zero conditions; a rule with no
+// conditions matches everything.
+type Rule struct {
+	// Action is required.
+	Action Action
+	// Levels restricts the rule to entries at these levels.
+	Levels []zap.Level
+	// MessagePrefix and MessageRegexp match against the message.
+	MessagePrefix string
+	MessageRegexp string
+	// Logger matches the logger's name, whether it was given to the wrapped
+	// logger before calling Filter or to the filtering logger with Named. A
+	// name matches its descendants too (e.g., "db" matches "db.pool"), and
+	// patterns with glob metacharacters are matched using path.Match.
+	Logger string
+	// CallerPackage matches the import path of the package that logged the
+	// entry, or any of its sub-packages.
+	CallerPackage string
+	// Field requires the entry to have a field with this key, either at the
+	// call site or in context added to the filtering logger with With. If
+	// FieldValue is also set, the field's value, formatted with fmt.Sprint,
+	// must equal it.
+	Field      string
+	FieldValue string
+}
+
+type compiledRule struct {
+	action   Action
+	levels   []zap.Level
+	prefix   string
+	re       *regexp.Regexp
+	logger   string
+	caller   string
+	field    string
+	value    string
+	hasValue bool
+}
+
+func compileRule(r Rule) (compiledRule, error) {
+	if r.Action != Keep && r.Action != Drop {
+		return compiledRule{}, errors.New("action must be Keep or Drop")
+	}
+	if r.FieldValue != "" && r.Field == "" {
+		return compiledRule{}, errors.New("FieldValue requires Field")
+	}
+	if r.Logger != "" {
+		if err := names.Validate(r.Logger); err != nil {
+			return compiledRule{}, fmt.Errorf("invalid logger pattern %q: %v", r.Logger, err)
+		}
+	}
+	cr := compiledRule{
+		action:   r.Action,
+		levels:   r.Levels,
+		prefix:   r.MessagePrefix,
+		logger:   r.Logger,
+		caller:   r.CallerPackage,
+		field:    r.Field,
+		value:    r.FieldValue,
+		hasValue: r.FieldValue != "",
+	}
+	if r.MessageRegexp != "" {
+		re, err := regexp.Compile(r.MessageRegexp)
+		if err != nil {
+			return compiledRule{}, err
+		}
+		cr.re = re
+	}
+	return cr, nil
+}
+
+// matchEntry checks every condition that doesn't depend on the entry's fields.
+func (r *compiledRule) matchEntry(lvl zap.Level, msg, name string, caller func() string) bool {
+	if len(r.levels) > 0 && !containsLevel(r.levels, lvl) {
+		return false
+	}
+	if !strings.HasPrefix(msg, r.prefix) {
+		return false
+	}
+	if r.re != nil && !r.re.MatchString(msg) {
+		return false
+	}
+	if r.logger != "" && !names.Match(r.logger, name) {
+		return false
+	}
+	if r.caller != "" && !inPackage(r.caller, caller()) {
+		return false
+	}
+	return true
+}
+
+func (r *compiledRule) matchFields(fields []zap.Field) bool {
+	for _, f := range fields {
+		if f.Key() == r.field && (!r.hasValue || fmt.Sprint(f.Value()) == r.value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsLevel(levels []zap.Level, lvl zap.Level) bool {
+	for _, l := range levels {
+		if l == lvl {
+			return true
+		}
+	}
+	return false
+}
+
+func inPackage(pkg, candidate string) bool {
+	return candidate == pkg || strings.HasPrefix(candidate, pkg+"/")
+}
+
+// callerPackage returns the import path of the first function on the stack
+// that isn't part of zap (test files excepted).
+func callerPackage() string {
+	for _, f := range frames.Callers(1, _maxCallerDepth) {
+		if !frames.InZap(f) {
+			return frames.Package(f.Function)
+		}
+	}
+	return ""
+}
+
+// Filter returns a logger that keeps or drops entries based on their content.
+// Rules are evaluated in order and the first matching rule decides the
+// entry's fate; entries that match no rule are kept. For example, to silence
+// a chatty library's connection-reset warnings:
+//
+//   logger, err := zwrap.Filter(base, zwrap.Rule{
+//     Action:        zwrap.Drop,
+//     Levels:        []zap.Level{zap.WarnLevel},
+//     MessagePrefix: "connection reset",
+//     CallerPackage: "github.com/chatty/library",
+//   })
+//
+// Conditions on the message, level, name, and caller are evaluated by Check;
+// field conditions are only evaluated when the CheckedMessage is written, and
+// only if no earlier rule has already decided the entry. Entries at
+// DPanicLevel and above are never filtered.
+//
+// Filter returns an error if any rule is invalid.
+func Filter(zl zap.Logger, rules ...Rule) (zap.Logger, error) {
+	compiled := make([]compiledRule, len(rules))
+	var needsCaller bool
+	for i, r := range rules {
+		cr, err := compileRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter rule %d: %v", i, err)
+		}
+		compiled[i] = cr
+		needsCaller = needsCaller || cr.caller != ""
+	}
+	name, _ := loggerName(zl)
+	return &filter{Logger: zl, name: name, rules: compiled, needsCaller: needsCaller}, nil
+}
+
+// loggerName returns the name of loggers that report one, like those that
+// embed a zap.Meta.
+func loggerName(l zap.Logger) (string, bool) {
+	if n, ok := l.(interface {
+		LoggerName() string
+	}); ok {
+		return n.LoggerName(), true
+	}
+	return "", false
+}
+
+type filter struct {
+	zap.Logger
+
+	name        string
+	rules       []compiledRule
+	needsCaller bool
+	// context holds the fields added with With, so that field rules can see
+	// them.
+	context []zap.Field
+}
+
+func (f *filter) clone(l zap.Logger) *filter {
+	return &filter{
+		Logger:      l,
+		name:        f.name,
+		rules:       f.rules,
+		needsCaller: f.needsCaller,
+		context:     f.context,
+	}
+}
+
+func (f *filter) With(fields ...zap.Field) zap.Logger {
+	clone := f.clone(f.Logger.With(fields...))
+	if f.needsFields() {
+		clone.context = make([]zap.Field, 0, len(f.context)+len(fields))
+		clone.context = append(clone.context, f.context...)
+		clone.context = append(clone.context, fields...)
+	}
+	return clone
+}
+
+func (f *filter) Named(name string) zap.Logger {
+	if name == "" {
+		return f
+	}
+	clone := f.clone(f.Logger.Named(name))
+	if n, ok := loggerName(clone.Logger); ok {
+		clone.name = n
+	} else if f.name != "" {
+		clone.name = f.name + "." + name
+	} else {
+		clone.name = name
+	}
+	return clone
+}
+
+// LoggerName returns the name that Logger rules match against.
+func (f *filter) LoggerName() string {
+	return f.name
+}
+
+// PanicValue forwards to the filtered logger, so that its PanicValueFunc
+// applies when the filter is part of a zap.Tee.
+func (f *filter) PanicValue(lvl zap.Level, msg string, fields []zap.Field) interface{} {
+	if p, ok := f.Logger.(interface {
+		PanicValue(zap.Level, string, []zap.Field) interface{}
+	}); ok {
+		return p.PanicValue(lvl, msg, fields)
+	}
+	return &zap.PanicError{Level: lvl, Message: msg, Fields: append([]zap.Field(nil), fields...)}
+}
+
+// Shutdown forwards to the filtered logger, so that its shutdown hooks run
+// when the filter is part of a zap.Tee.
+func (f *filter) Shutdown() (exit func(int), code int) {
+	if s, ok := f.Logger.(interface {
+		Shutdown() (func(int), int)
+	}); ok {
+		return s.Shutdown()
+	}
+	return nil, 1
+}
+
+// needsFields reports whether any rule has a field condition.
+func (f *filter) needsFields() bool {
+	for i := range f.rules {
+		if f.rules[i].field != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// decide evaluates rules without looking at fields. It returns the index of
+// the first rule that matched but still needs its field conditions checked,
+// or -1 if the entry's fate is settled by keep.
+func (f *filter) decide(lvl zap.Level, msg string) (pending int, keep bool, caller string) {
+	if lvl >= zap.DPanicLevel {
+		return -1, true, ""
+	}
+	var cached bool
+	getCaller := func() string {
+		if !cached {
+			caller, cached = callerPackage(), true
+		}
+		return caller
+	}
+	for i := range f.rules {
+		r := &f.rules[i]
+		if !r.matchEntry(lvl, msg, f.name, getCaller) {
+			continue
+		}
+		if r.field != "" {
+			if f.needsCaller {
+				getCaller()
+			}
+			return i, false, caller
+		}
+		return -1, r.action == Keep, caller
+	}
+	return -1, true, caller
+}
+
+// decideFields resumes rule evaluation at the given index, this time checking
+// field conditions too.
+func (f *filter) decideFields(start int, lvl zap.Level, msg, caller string, fields []zap.Field) bool {
+	if len(f.context) > 0 {
+		all := make([]zap.Field, 0, len(f.context)+len(fields))
+		all = append(all, f.context...)
+		fields = append(all, fields...)
+	}
+	getCaller := func() string { return caller }
+	for i := start; i < len(f.rules); i++ {
+		r := &f.rules[i]
+		if !r.matchEntry(lvl, msg, f.name, getCaller) {
+			continue
+		}
+		if r.field != "" && !r.matchFields(fields) {
+			continue
+		}
+		return r.action == Keep
+	}
+	return true
+}
+
+func (f *filter) allowed(lvl zap.Level, msg string, fields []zap.Field) bool {
+	pending, keep, caller := f.decide(lvl, msg)
+	if pending < 0 {
+		return keep
+	}
+	return f.decideFields(pending, lvl, msg, caller, fields)
+}
+
+func (f *filter) Check(lvl zap.Level, msg string) *zap.CheckedMessage {
+	pending, keep, caller := f.decide(lvl, msg)
+	if pending < 0 {
+		if !keep {
+			return nil
+		}
+		return f.Logger.Check(lvl, msg)
+	}
+	cm := f.Logger.Check(lvl, msg)
+	if !cm.OK() {
+		return nil
+	}
+	return zap.NewCheckedMessage(&fieldFilter{filter: f, start: pending, caller: caller, cm: cm}, lvl, msg)
+}
+
+func (f *filter) Log(lvl zap.Level, msg string, fields ...zap.Field) {
+	if f.allowed(lvl, msg, fields) {
+		f.Logger.Log(lvl, msg, fields...)
+	}
+}
+
+func (f *filter) Debug(msg string, fields ...zap.Field) {
+	if f.allowed(zap.DebugLevel, msg, fields) {
+		f.Logger.Debug(msg, fields...)
+	}
+}
+
+func (f *filter) Info(msg string, fields ...zap.Field) {
+	if f.allowed(zap.InfoLevel, msg, fields) {
+		f.Logger.Info(msg, fields...)
+	}
+}
+
+func (f *filter) Warn(msg string, fields ...zap.Field) {
+	if f.allowed(zap.WarnLevel, msg, fields) {
+		f.Logger.Warn(msg, fields...)
+	}
+}
+
+func (f *filter) Error(msg string, fields ...zap.Field) {
+	if f.allowed(zap.ErrorLevel, msg, fields) {
+		f.Logger.Error(msg, fields...)
+	}
+}
+
+// fieldFilter finishes evaluating a checked entry's rules once its fields are
+// known, writing the underlying logger's CheckedMessage if the entry is kept.
+// It's only used for levels below DPanic, which are never pending.
+type fieldFilter struct {
+	*filter
+
+	start  int
+	caller string
+	cm     *zap.CheckedMessage
+}
+
+func (ff *fieldFilter) write(lvl zap.Level, msg string, fields []zap.Field) {
+	if ff.decideFields(ff.start, lvl, msg, ff.caller, fields) {
+		ff.cm.Write(fields...)
+	}
+}
+
+func (ff *fieldFilter) Log(lvl zap.Level, msg string, fields ...zap.Field) {
+	ff.write(lvl, msg, fields)
+}
+
+func (ff *fieldFilter) Debug(msg string, fields ...zap.Field) {
+	ff.write(zap.DebugLevel, msg, fields)
+}
+
+func (ff *fieldFilter) Info(msg string, fields ...zap.Field) {
+	ff.write(zap.InfoLevel, msg, fields)
+}
+
+func (ff *fieldFilter) Warn(msg string, fields ...zap.Field) {
+	ff.write(zap.WarnLevel, msg, fields)
+}
+
+func (ff *fieldFilter) Error(msg string, fields ...zap.Field) {
+	ff.write(zap.ErrorLevel, msg, fields)
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zwrap
+
+import (
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeFilter(t testing.TB, rules ...Rule) (zap.Logger, *spy.Sink) {
+	base, sink := spy.New(zap.DebugLevel)
+	logger, err := Filter(base, rules...)
+	require.NoError(t, err, "Unexpected error constructing filter.")
+	return logger, sink
+}
+
+func messages(sink *spy.Sink) []string {
+	var msgs []string
+	for _, l := range sink.Logs() {
+		msgs = append(msgs, l.Msg)
+	}
+	return msgs
+}
+
+func TestFilterRules(t *testing.T) {
+	tests := []struct {
+		desc  string
+		rules []Rule
+		log   func(zap.Logger)
+		want  []string
+	}{
+		{
+			desc:  "no rules",
+			rules: nil,
+			log:   func(l zap.Logger) { l.Info("a") },
+			want:  []string{"a"},
+		},
+		{
+			desc:  "prefix and level",
+			rules: []Rule{{Action: Drop, Levels: []zap.Level{zap.WarnLevel}, MessagePrefix: "connection reset"}},
+			log: func(l zap.Logger) {
+				l.Warn("connection reset by peer")
+				l.Error("connection reset by peer")
+				l.Warn("disk full")
+			},
+			want: []string{"connection reset by peer", "disk full"},
+		},
+		{
+			desc:  "regexp",
+			rules: []Rule{{Action: Drop, MessageRegexp: `^retry \d+$`}},
+			log: func(l zap.Logger) {
+				l.Info("retry 1")
+				l.Info("retry later")
+			},
+			want: []string{"retry later"},
+		},
+		{
+			desc: "first match wins",
+			rules: []Rule{
+				{Action: Keep, MessagePrefix: "important"},
+				{Action: Drop},
+			},
+			log: func(l zap.Logger) {
+				l.Info("important thing")
+				l.Info("noise")
+			},
+			want: []string{"important thing"},
+		},
+		{
+			desc:  "logger name",
+			rules: []Rule{{Action: Drop, Logger: "db"}},
+			log: func(l zap.Logger) {
+				l.Info("root")
+				l.Named("db").Info("db")
+				l.Named("db").Named("pool").Info("db.pool")
+				l.Named("dbx").Info("dbx")
+			},
+			want: []string{"root", "dbx"},
+		},
+		{
+			desc:  "logger glob",
+			rules: []Rule{{Action: Drop, Logger: "*.pool"}},
+			log: func(l zap.Logger) {
+				l.Named("db").Info("db")
+				l.Named("db").Named("pool").Info("db.pool")
+			},
+			want: []string{"db"},
+		},
+		{
+			desc:  "caller package",
+			rules: []Rule{{Action: Drop, CallerPackage: "github.com/uber-go/zap/zwrap"}},
+			log:   func(l zap.Logger) { l.Info("from zwrap") },
+			want:  nil,
+		},
+		{
+			desc:  "other caller package",
+			rules: []Rule{{Action: Drop, CallerPackage: "github.com/uber-go/zap/zwrapper"}},
+			log:   func(l zap.Logger) { l.Info("from zwrap") },
+			want:  []string{"from zwrap"},
+		},
+		{
+			desc:  "field presence",
+			rules: []Rule{{Action: Drop, Field: "healthcheck"}},
+			log: func(l zap.Logger) {
+				l.Info("probe", zap.Bool("healthcheck", true))
+				l.Info("request")
+				l.With(zap.Bool("healthcheck", true)).Info("context")
+				l.With(zap.String("user", "x")).Named("db").With(zap.Bool("healthcheck", true)).Info("nested context")
+			},
+			want: []string{"request"},
+		},
+		{
+			desc:  "field value",
+			rules: []Rule{{Action: Drop, Field: "status", FieldValue: "200"}},
+			log: func(l zap.Logger) {
+				l.Info("ok", zap.Int("status", 200))
+				l.Info("not found", zap.Int("status", 404))
+			},
+			want: []string{"not found"},
+		},
+		{
+			desc:  "field value in context",
+			rules: []Rule{{Action: Drop, Field: "status", FieldValue: "200"}},
+			log: func(l zap.Logger) {
+				l.With(zap.Int("status", 200)).Info("ok")
+				l.With(zap.Int("status", 404)).Info("not found")
+			},
+			want: []string{"not found"},
+		},
+		{
+			desc: "field rule falls through",
+			rules: []Rule{
+				{Action: Keep, Field: "audit"},
+				{Action: Drop, Levels: []zap.Level{zap.DebugLevel}},
+			},
+			log: func(l zap.Logger) {
+				l.Debug("audited", zap.String("audit", "yes"))
+				l.Debug("dropped")
+			},
+			want: []string{"audited"},
+		},
+		{
+			desc:  "Log method",
+			rules: []Rule{{Action: Drop, MessagePrefix: "x"}},
+			log: func(l zap.Logger) {
+				l.Log(zap.InfoLevel, "x")
+				l.Log(zap.InfoLevel, "y")
+			},
+			want: []string{"y"},
+		},
+		{
+			desc:  "DPanic is never filtered",
+			rules: []Rule{{Action: Drop}},
+			log:   func(l zap.Logger) { l.DPanic("dpanic") },
+			want:  []string{"dpanic"},
+		},
+	}
+
+	for _, tt := range tests {
+		logger, sink := fakeFilter(t, tt.rules...)
+		tt.log(logger)
+		assert.Equal(t, tt.want, messages(sink), "Unexpected output for %s.", tt.desc)
+	}
+}
+
+func TestFilterNamedBase(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	logger, err := Filter(base.Named("db"), Rule{Action: Drop, Logger: "db"})
+	require.NoError(t, err, "Unexpected error constructing filter.")
+
+	logger.Info("dropped")
+	logger.Named("pool").Info("also dropped")
+
+	other, err := Filter(base.Named("http"), Rule{Action: Drop, Logger: "http.client"})
+	require.NoError(t, err, "Unexpected error constructing filter.")
+	other.Info("kept")
+	other.Named("client").Info("dropped")
+
+	assert.Equal(t, []string{"kept"}, messages(sink), "Expected rules to match the wrapped logger's name.")
+}
+
+func TestFilterCheck(t *testing.T) {
+	logger, sink := fakeFilter(t,
+		Rule{Action: Drop, MessagePrefix: "noise"},
+		Rule{Action: Drop, Field: "status", FieldValue: "200"},
+	)
+
+	assert.Nil(t, logger.Check(zap.InfoLevel, "noise"), "Expected static rules to be applied by Check.")
+
+	cm := logger.Check(zap.InfoLevel, "request")
+	require.True(t, cm.OK(), "Expected field rules to be deferred until Write.")
+	cm.Write(zap.Int("status", 200))
+
+	cm = logger.Check(zap.WarnLevel, "request")
+	require.True(t, cm.OK(), "Expected field rules to be deferred until Write.")
+	cm.Write(zap.Int("status", 500))
+
+	assert.Equal(t, []spy.Log{{
+		Level:  zap.WarnLevel,
+		Msg:    "request",
+		Fields: []zap.Field{zap.Int("status", 500)},
+	}}, sink.Logs(), "Unexpected output from checked messages.")
+}
+
+func TestFilterCheckDisabledLevel(t *testing.T) {
+	base, _ := spy.New(zap.InfoLevel)
+	logger, err := Filter(base, Rule{Action: Drop, Field: "x"})
+	require.NoError(t, err, "Unexpected error constructing filter.")
+	assert.Nil(t, logger.Check(zap.DebugLevel, "disabled"), "Expected disabled levels to stay disabled.")
+}
+
+func TestFilterInvalidRules(t *testing.T) {
+	tests := []Rule{
+		{},
+		{Action: Drop, MessageRegexp: "("},
+		{Action: Drop, Logger: "["},
+		{Action: Drop, FieldValue: "value"},
+	}
+	for _, r := range tests {
+		_, err := Filter(zap.New(zap.NullEncoder()), r)
+		assert.Error(t, err, "Expected an error for invalid rule %+v.", r)
+	}
+}
+
+func TestFilterInTee(t *testing.T) {
+	var (
+		hooks int
+		codes []int
+	)
+	base, _ := spy.New(
+		zap.PanicValueFunc(func(lvl zap.Level, msg string, fields []zap.Field) interface{} { return msg + "!" }),
+		zap.OnShutdown(func() { hooks++ }),
+		zap.ExitFunc(func(code int) { codes = append(codes, code) }),
+		zap.ExitCode(3),
+	)
+	filtered, err := Filter(base, Rule{Action: Drop, MessagePrefix: "noise"})
+	require.NoError(t, err, "Unexpected error constructing filter.")
+	other, _ := spy.New()
+	logger := zap.Tee(filtered, other)
+
+	var recovered interface{}
+	func() {
+		defer func() { recovered = recover() }()
+		logger.Panic("foo")
+	}()
+	assert.Equal(t, "foo!", recovered, "Expected the filtered logger's PanicValueFunc to apply.")
+
+	logger.Fatal("foo")
+	assert.Equal(t, 1, hooks, "Expected the filtered logger's shutdown hooks to run.")
+	assert.Equal(t, []int{3}, codes, "Expected the filtered logger's exit function and code.")
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zwrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/uber-go/zap"
+)
+
+// _defaultMaxLineLength is the longest line a LineWriter buffers by default.
+const _defaultMaxLineLength = 64 * 1024
+
+// The keys of zap's default JSON encoder, which ParseJSON consumes.
+const (
+	_jsonMessageKey = "msg"
+	_jsonLevelKey   = "level"
+	_jsonTimeKey    = "ts"
+)
+
+// A LineWriterOption configures a LineWriter.
+type LineWriterOption interface {
+	apply(*LineWriter)
+}
+
+type lineWriterOptionFunc func(*LineWriter)
+
+func (f lineWriterOptionFunc) apply(w *LineWriter) {
+	f(w)
+}
+
+// MaxLineLength sets the number of bytes a LineWriter buffers before logging a
+// line, even if it hasn't seen a newline; the rest of the line is logged as
+// one or more separate entries. Lines are split between UTF-8 characters, so
+// a chunk may be up to three bytes shorter than the limit. Non-positive values
+// remove the limit. The default is 64KiB.
+func MaxLineLength(n int) LineWriterOption {
+	return lineWriterOptionFunc(func(w *LineWriter) {
+		w.maxLen = n
+	})
+}
+
+// ParseJSON configures a LineWriter to decode lines that are JSON objects,
+// such as the output of a child process using zap's default JSON encoder.
+// The "msg" and "level" keys become the entry's message and level, the "ts"
+// key is dropped (the entry is timestamped when it's logged), and all other
+// keys become fields. Lines that aren't JSON objects are logged as usual.
+func ParseJSON() LineWriterOption {
+	return lineWriterOptionFunc(func(w *LineWriter) {
+		w.parseJSON = true
+	})
+}
+
+// A LineWriter is an io.Writer that logs each line written to it as a separate
+// entry, which makes it a convenient destination for the output of
+// subprocesses and libraries that only know how to write text:
+//
+//   cmd.Stdout, _ = zwrap.NewLineWriter(logger, zap.InfoLevel)
+//
+// Lines may be split across any number of writes. Trailing carriage returns
+// are trimmed and empty lines are ignored. Partial lines are buffered until a
+// newline arrives, the line grows past the maximum length, or Sync is called.
+// LineWriters are safe for concurrent use.
+type LineWriter struct {
+	logger    zap.Logger
+	lvl       zap.Level
+	maxLen    int
+	parseJSON bool
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewLineWriter creates a LineWriter that logs lines at the given level. As
+// with Standardize, the level must be Debug, Info, Warn, or Error; otherwise,
+// NewLineWriter returns ErrInvalidLevel.
+func NewLineWriter(l zap.Logger, lvl zap.Level, options ...LineWriterOption) (*LineWriter, error) {
+	if !printable(lvl) {
+		return nil, ErrInvalidLevel
+	}
+	w := &LineWriter{
+		logger: l,
+		lvl:    lvl,
+		maxLen: _defaultMaxLineLength,
+	}
+	for _, opt := range options {
+		opt.apply(w)
+	}
+	return w, nil
+}
+
+// Write logs every complete line in p and buffers the remainder. It always
+// consumes all of p.
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		idx := bytes.IndexByte(p, '\n')
+		if idx < 0 {
+			w.buf = append(w.buf, p...)
+			w.flushLong()
+			break
+		}
+		w.buf = append(w.buf, p[:idx]...)
+		w.flushLong()
+		w.flush()
+		p = p[idx+1:]
+	}
+	return n, nil
+}
+
+// Sync logs any buffered partial line, then syncs the underlying Logger.
+func (w *LineWriter) Sync() error {
+	w.mu.Lock()
+	w.flush()
+	w.mu.Unlock()
+	return w.logger.Sync()
+}
+
+// flushLong logs maximum-length chunks of the buffer until it's shorter than
+// the limit.
+func (w *LineWriter) flushLong() {
+	if w.maxLen <= 0 {
+		return
+	}
+	for len(w.buf) >= w.maxLen {
+		n := cutPoint(w.buf, w.maxLen)
+		w.log(w.buf[:n])
+		w.buf = w.buf[:copy(w.buf, w.buf[n:])]
+	}
+}
+
+// cutPoint returns the largest index no greater than max at which b can be
+// split without splitting a UTF-8 character. If b isn't valid UTF-8 near max,
+// or a single character is longer than max, it returns max.
+func cutPoint(b []byte, max int) int {
+	if max >= len(b) {
+		return len(b)
+	}
+	for i := max; i > 0 && i > max-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			return i
+		}
+	}
+	return max
+}
+
+func (w *LineWriter) flush() {
+	w.log(w.buf)
+	w.buf = w.buf[:0]
+}
+
+func (w *LineWriter) log(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+	if w.parseJSON && line[0] == '{' && w.logJSON(line) {
+		return
+	}
+	if cm := w.logger.Check(w.lvl, string(line)); cm.OK() {
+		cm.Write()
+	}
+}
+
+// logJSON logs a line that's a JSON object, returning false if it isn't one.
+// Since the line was already written once, its level is taken at face value:
+// DPanic, Panic, and Fatal entries are logged with Log, so they never panic
+// or exit. Fields are only built if the entry's level is enabled. Integers are
+// logged as Int64 fields and other numbers as Float64 fields.
+func (w *LineWriter) logJSON(line []byte) bool {
+	var obj map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return false
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		// There's more than one JSON value on the line.
+		return false
+	}
+
+	lvl := w.lvl
+	if s, ok := obj[_jsonLevelKey].(string); ok {
+		var parsed zap.Level
+		if err := parsed.UnmarshalText([]byte(s)); err == nil {
+			lvl = parsed
+			delete(obj, _jsonLevelKey)
+		}
+	}
+	msg, ok := obj[_jsonMessageKey].(string)
+	if ok {
+		delete(obj, _jsonMessageKey)
+	}
+	delete(obj, _jsonTimeKey)
+
+	var cm *zap.CheckedMessage
+	if printable(lvl) {
+		if cm = w.logger.Check(lvl, msg); !cm.OK() {
+			return true
+		}
+	} else if enab, ok := w.logger.(zap.LevelEnabler); ok && !enab.Enabled(lvl) {
+		// Checking at these levels would return a CheckedMessage that panics
+		// or exits when written, so ask the level directly.
+		return true
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]zap.Field, len(keys))
+	for i, k := range keys {
+		fields[i] = jsonField(k, obj[k])
+	}
+	if cm != nil {
+		cm.Write(fields...)
+	} else {
+		w.logger.Log(lvl, msg, fields...)
+	}
+	return true
+}
+
+// jsonField converts a decoded JSON value to a Field. Nested numbers are left
+// as json.Numbers, which encode as the original literal.
+func jsonField(key string, v interface{}) zap.Field {
+	n, ok := v.(json.Number)
+	if !ok {
+		return zap.Any(key, v)
+	}
+	if i, err := n.Int64(); err == nil {
+		return zap.Int64(key, i)
+	}
+	if f, err := n.Float64(); err == nil {
+		return zap.Float64(key, f)
+	}
+	return zap.String(key, n.String())
+}
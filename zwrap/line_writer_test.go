@@ -0,0 +1,162 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zwrap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+	"github.com/uber-go/zap/spywrite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLineWriter(t testing.TB, options ...LineWriterOption) (*LineWriter, *spy.Sink) {
+	logger, sink := spy.New(zap.DebugLevel)
+	w, err := NewLineWriter(logger, zap.InfoLevel, options...)
+	require.NoError(t, err, "Unexpected error constructing a LineWriter.")
+	return w, sink
+}
+
+func TestLineWriterLines(t *testing.T) {
+	w, sink := newLineWriter(t)
+	var _ io.Writer = w
+
+	for _, chunk := range []string{"first line\nsec", "ond ", "line\r\n\n", "partial"} {
+		n, err := w.Write([]byte(chunk))
+		require.NoError(t, err, "Unexpected error writing.")
+		assert.Equal(t, len(chunk), n, "Expected the whole chunk to be consumed.")
+	}
+	assert.Equal(t, []string{"first line", "second line"}, messages(sink), "Unexpected entries before Sync.")
+
+	require.NoError(t, w.Sync(), "Unexpected error syncing.")
+	assert.Equal(t, []string{"first line", "second line", "partial"}, messages(sink), "Expected Sync to flush the partial line.")
+	assert.Equal(t, 1, sink.Syncs(), "Expected Sync to sync the underlying logger.")
+	for _, l := range sink.Logs() {
+		assert.Equal(t, zap.InfoLevel, l.Level, "Unexpected level.")
+	}
+}
+
+func TestLineWriterMaxLineLength(t *testing.T) {
+	w, sink := newLineWriter(t, MaxLineLength(4))
+	fmt.Fprint(w, "abcdefghij\nxyz\n")
+	fmt.Fprint(w, "ab")
+	fmt.Fprint(w, "cd")
+	assert.Equal(t, []string{"abcd", "efgh", "ij", "xyz", "abcd"}, messages(sink), "Expected long lines to be split.")
+
+	w, sink = newLineWriter(t, MaxLineLength(0))
+	fmt.Fprint(w, string(make([]byte, 2*_defaultMaxLineLength)))
+	assert.Empty(t, sink.Logs(), "Expected no limit with a non-positive maximum.")
+}
+
+func TestLineWriterMaxLineLengthUTF8(t *testing.T) {
+	w, sink := newLineWriter(t, MaxLineLength(4))
+	fmt.Fprint(w, "ab€cd\n")
+	fmt.Fprint(w, "日本語\n")
+	assert.Equal(t, []string{"ab", "€c", "d", "日", "本", "語"}, messages(sink), "Expected long lines to be split between characters.")
+
+	w, sink = newLineWriter(t, MaxLineLength(2))
+	fmt.Fprint(w, "€\n")
+	assert.Equal(t, []string{"\xe2\x82", "\xac"}, messages(sink), "Expected characters longer than the limit to be split.")
+}
+
+func TestLineWriterParseJSONChecksLevel(t *testing.T) {
+	logger, sink := spy.New(zap.WarnLevel, zap.Development())
+	w, err := NewLineWriter(logger, zap.InfoLevel, ParseJSON())
+	require.NoError(t, err, "Unexpected error constructing a LineWriter.")
+
+	fmt.Fprintln(w, `{"level":"info","msg":"disabled","n":1}`)
+	assert.NotPanics(t, func() {
+		fmt.Fprintln(w, `{"level":"dpanic","msg":"doesn't panic"}`)
+	}, "Expected DPanic lines not to panic in development.")
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.DPanicLevel, Msg: "doesn't panic", Fields: []zap.Field{}},
+	}, sink.Logs(), "Expected only enabled JSON lines to be logged.")
+}
+
+func TestLineWriterParseJSONSkipsDisabledTerminalLevels(t *testing.T) {
+	logger, sink := spy.New(zap.LevelEnablerFunc(func(lvl zap.Level) bool { return lvl < zap.DPanicLevel }))
+	w, err := NewLineWriter(logger, zap.InfoLevel, ParseJSON())
+	require.NoError(t, err, "Unexpected error constructing a LineWriter.")
+
+	fmt.Fprintln(w, `{"level":"panic","msg":"disabled"}`)
+	fmt.Fprintln(w, `{"level":"fatal","msg":"disabled"}`)
+	fmt.Fprintln(w, `{"level":"error","msg":"enabled"}`)
+	assert.Equal(t, []string{"enabled"}, messages(sink), "Expected disabled Panic and Fatal lines to be dropped.")
+}
+
+func TestLineWriterParseJSONNumbers(t *testing.T) {
+	w, sink := newLineWriter(t, ParseJSON())
+	fmt.Fprintln(w, `{"msg":"numbers","big":9007199254740993,"float":1.5,"exp":1e3,"huge":1e400,"nested":{"n":9007199254740993}}`)
+	fmt.Fprintln(w, `{"msg":"one"} {"msg":"two"}`)
+
+	logs := sink.Logs()
+	require.Equal(t, 2, len(logs), "Unexpected number of entries.")
+	assert.Equal(t, []zap.Field{
+		zap.Int64("big", 9007199254740993),
+		zap.Float64("exp", 1000),
+		zap.Float64("float", 1.5),
+		zap.String("huge", "1e400"),
+		zap.Any("nested", map[string]interface{}{"n": json.Number("9007199254740993")}),
+	}, logs[0].Fields, "Unexpected fields from JSON numbers.")
+	assert.Equal(t, `{"msg":"one"} {"msg":"two"}`, logs[1].Msg, "Expected lines with several JSON values to be logged verbatim.")
+}
+
+func TestLineWriterParseJSON(t *testing.T) {
+	w, sink := newLineWriter(t, ParseJSON())
+	fmt.Fprintln(w, `{"level":"warn","ts":1,"msg":"child","b":true,"a":"x"}`)
+	fmt.Fprintln(w, `{"level":"fatal","msg":"doesn't exit"}`)
+	fmt.Fprintln(w, `{"level":"bogus","n":1}`)
+	fmt.Fprintln(w, `{not json`)
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.WarnLevel, Msg: "child", Fields: []zap.Field{zap.String("a", "x"), zap.Bool("b", true)}},
+		{Level: zap.FatalLevel, Msg: "doesn't exit", Fields: []zap.Field{}},
+		{Level: zap.InfoLevel, Fields: []zap.Field{zap.String("level", "bogus"), zap.Int64("n", 1)}},
+		{Level: zap.InfoLevel, Msg: "{not json", Fields: []zap.Field{}},
+	}, sink.Logs(), "Unexpected entries from JSON lines.")
+
+	w, sink = newLineWriter(t)
+	fmt.Fprintln(w, `{"msg":"raw"}`)
+	assert.Equal(t, []string{`{"msg":"raw"}`}, messages(sink), "Expected JSON to be logged verbatim without ParseJSON.")
+}
+
+func TestLineWriterInvalidLevel(t *testing.T) {
+	logger, _ := spy.New()
+	_, err := NewLineWriter(logger, zap.PanicLevel)
+	assert.Equal(t, ErrInvalidLevel, err, "Expected ErrInvalidLevel.")
+}
+
+func TestLineWriterSyncError(t *testing.T) {
+	failing := &spywrite.WriteSyncer{Writer: ioutil.Discard}
+	failing.SetError(errors.New("failed"))
+	w, err := NewLineWriter(zap.New(zap.NewJSONEncoder(), zap.Output(failing)), zap.InfoLevel)
+	require.NoError(t, err, "Unexpected error constructing a LineWriter.")
+	assert.EqualError(t, w.Sync(), "failed", "Expected the underlying logger's Sync error.")
+}
@@ -152,6 +152,26 @@ func TestSamplerWithSharesCounters(t *testing.T) {
 	assert.Equal(t, expected, sink.Logs(), "Expected child loggers to share counters.")
 }
 
+func TestSamplerNamedSharesCounters(t *testing.T) {
+	logger, sink := fakeSampler(zap.DebugLevel, time.Minute, 1, 100, false)
+
+	logger.Named("first").Info("sample")
+	logger.Named("second").Info("sample")
+
+	assert.Equal(t, []spy.Log{{
+		Level:  zap.InfoLevel,
+		Name:   "first",
+		Msg:    "sample",
+		Fields: []zap.Field{},
+	}}, sink.Logs(), "Expected named loggers to keep the name and share counters.")
+}
+
+func TestSamplerSync(t *testing.T) {
+	logger, sink := fakeSampler(zap.DebugLevel, time.Minute, 1, 100, false)
+	assert.NoError(t, logger.With(zap.Int("foo", 1)).Sync(), "Unexpected error syncing sampler.")
+	assert.Equal(t, 1, sink.Syncs(), "Expected Sync to reach the underlying logger.")
+}
+
 func TestSamplerTicks(t *testing.T) {
 	// Ensure that we're resetting the sampler's counter every tick.
 	sampler, sink := fakeSampler(zap.DebugLevel, time.Millisecond, 1, 1000, false)
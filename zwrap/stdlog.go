@@ -0,0 +1,108 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zwrap
+
+import (
+	"log"
+	"strings"
+
+	"github.com/uber-go/zap"
+)
+
+// A LevelPrefix logs messages that start with Prefix at Level. If Trim is
+// true, the prefix and any whitespace following it are removed from the
+// message.
+type LevelPrefix struct {
+	Prefix string
+	Level  zap.Level
+	Trim   bool
+}
+
+// NewStdLog returns a *log.Logger that writes to the supplied Logger, for use
+// with dependencies that require the concrete standard library type (for
+// example, net/http.Server's ErrorLog). Each call to the *log.Logger becomes
+// a single entry at the given level, with trailing newlines trimmed and the
+// caller (if enabled with zap.AddCaller or zap.AddCallerField) reported as the
+// code calling the *log.Logger.
+//
+// Messages that start with one of the given prefixes are logged at that
+// prefix's level instead; the first matching prefix wins. For example:
+//
+//   errorLog, err := zwrap.NewStdLog(logger, zap.InfoLevel,
+//     zwrap.LevelPrefix{Prefix: "[ERROR]", Level: zap.ErrorLevel, Trim: true},
+//     zwrap.LevelPrefix{Prefix: "http: TLS handshake error", Level: zap.DebugLevel},
+//   )
+//
+// As with Standardize, all levels must be Debug, Info, Warn, or Error;
+// otherwise, NewStdLog returns ErrInvalidLevel. Since the *log.Logger's
+// Panic and Fatal methods don't consult the writer, they still panic and exit
+// after logging at the chosen level.
+func NewStdLog(l zap.Logger, lvl zap.Level, prefixes ...LevelPrefix) (*log.Logger, error) {
+	if !printable(lvl) {
+		return nil, ErrInvalidLevel
+	}
+	for _, p := range prefixes {
+		if !printable(p.Level) {
+			return nil, ErrInvalidLevel
+		}
+	}
+	w := &stdLogWriter{
+		logger:   l,
+		lvl:      lvl,
+		prefixes: append([]LevelPrefix(nil), prefixes...),
+	}
+	return log.New(w, "" /* prefix */, 0 /* flags */), nil
+}
+
+func printable(lvl zap.Level) bool {
+	switch lvl {
+	case zap.DebugLevel, zap.InfoLevel, zap.WarnLevel, zap.ErrorLevel:
+		return true
+	default:
+		return false
+	}
+}
+
+// stdLogWriter is the io.Writer behind NewStdLog. The *log.Logger calls Write
+// exactly once for each message.
+type stdLogWriter struct {
+	logger   zap.Logger
+	lvl      zap.Level
+	prefixes []LevelPrefix
+}
+
+func (w *stdLogWriter) Write(b []byte) (int, error) {
+	msg := strings.TrimRight(string(b), "\r\n")
+	lvl := w.lvl
+	for _, p := range w.prefixes {
+		if strings.HasPrefix(msg, p.Prefix) {
+			lvl = p.Level
+			if p.Trim {
+				msg = strings.TrimLeft(msg[len(p.Prefix):], " \t")
+			}
+			break
+		}
+	}
+	if cm := w.logger.Check(lvl, msg); cm.OK() {
+		cm.Write()
+	}
+	return len(b), nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zwrap
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStdLogLevels(t *testing.T) {
+	logger, sink := spy.New(zap.DebugLevel)
+	std, err := NewStdLog(logger, zap.InfoLevel,
+		LevelPrefix{Prefix: "[ERROR]", Level: zap.ErrorLevel, Trim: true},
+		LevelPrefix{Prefix: "http: TLS handshake error", Level: zap.DebugLevel},
+	)
+	require.NoError(t, err, "Unexpected error constructing a *log.Logger.")
+
+	std.Print("plain")
+	std.Println("with newline")
+	std.Printf("[ERROR]  failed %d times\n", 3)
+	std.Print("http: TLS handshake error from 10.0.0.1: EOF")
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.InfoLevel, Msg: "plain", Fields: []zap.Field{}},
+		{Level: zap.InfoLevel, Msg: "with newline", Fields: []zap.Field{}},
+		{Level: zap.ErrorLevel, Msg: "failed 3 times", Fields: []zap.Field{}},
+		{Level: zap.DebugLevel, Msg: "http: TLS handshake error from 10.0.0.1: EOF", Fields: []zap.Field{}},
+	}, sink.Logs(), "Unexpected output from *log.Logger.")
+}
+
+func TestNewStdLogDisabledLevel(t *testing.T) {
+	logger, sink := spy.New(zap.WarnLevel)
+	std, err := NewStdLog(logger, zap.InfoLevel)
+	require.NoError(t, err, "Unexpected error constructing a *log.Logger.")
+	std.Print("disabled")
+	assert.Empty(t, sink.Logs(), "Expected messages at disabled levels to be dropped.")
+}
+
+func TestNewStdLogInvalidLevels(t *testing.T) {
+	logger, _ := spy.New()
+	for _, lvl := range []zap.Level{zap.PanicLevel, zap.FatalLevel, zap.Level(42)} {
+		_, err := NewStdLog(logger, lvl)
+		assert.Equal(t, ErrInvalidLevel, err, "Expected ErrInvalidLevel for level %v.", lvl)
+
+		_, err = NewStdLog(logger, zap.InfoLevel, LevelPrefix{Prefix: "x", Level: lvl})
+		assert.Equal(t, ErrInvalidLevel, err, "Expected ErrInvalidLevel for prefix level %v.", lvl)
+	}
+}
+
+func TestNewStdLogCaller(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := zap.New(zap.NewJSONEncoder(), zap.Output(zap.AddSync(buf)), zap.AddCallerField())
+	std, err := NewStdLog(logger, zap.InfoLevel)
+	require.NoError(t, err, "Unexpected error constructing a *log.Logger.")
+
+	std.Print("where am I?")
+	assert.Regexp(t, regexp.MustCompile(`"caller":"stdlog_test.go:\d+"`), buf.String(), "Expected the caller to be the *log.Logger's caller.")
+}